@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"lsti/mes"
+)
+
+// IsRegression reports whether d increased beyond both thresholds, or decreased
+// if higher is better for the metric (see isHigherBetter).
+// maxAbsolute is in the unit of d, and maxRelative is in percent.
+// A value of baseline missing in candidate (e.g. the run crashed before printing timings) is a regression.
+func (d *Difference) IsRegression(maxAbsolute, maxRelative float64) bool {
+	if d.HasBaseline && !d.HasCandidate {
+		return true
+	}
+	if !d.Comparable() {
+		return false
+	}
+	change, relative := d.Change(), d.RelativeChange()
+	if isHigherBetter(d.Metric) {
+		change, relative = -change, -relative
+	}
+	if change <= maxAbsolute {
+		return false
+	}
+	return math.IsNaN(relative) || relative > maxRelative
+}
+
+// FindRegressions returns elapsed time and timings of "-t, --target" (one or more) that regressed
+// from baseline to candidate beyond the thresholds of "check" command.
+// Elapsed time is missing in candidate if any candidate run has no elapsed time.
+func FindRegressions(baseline, candidate []*mes.Record) []*Difference {
+	var regressions []*Difference
+	for _, d := range ComputeDifferences(baseline, candidate, targets()) {
+		// Number of CPUs is not a timing.
+		if d.Metric == "" {
+			continue
+		}
+		if d.Name == "elapsedTime" {
+			for _, record := range candidate {
+				if record.ElapsedTime == 0 {
+					d.HasCandidate = false
+				}
+			}
+		}
+		if d.IsRegression(opts.Check.MaxAbsolute, opts.Check.MaxRelative) {
+			regressions = append(regressions, d)
+		}
+	}
+	return regressions
+}
+
+// FindAbnormalTerminations returns candidate records that are not normally terminated,
+// or nil if "--allow-abnormal" option is specified.
+func FindAbnormalTerminations(candidate []*mes.Record) []*mes.Record {
+	if opts.Check.AllowAbnormal {
+		return nil
+	}
+	var abnormal []*mes.Record
+	for _, record := range candidate {
+		if record.Termination.Class != mes.NormalTermination {
+			abnormal = append(abnormal, record)
+		}
+	}
+	return abnormal
+}
+
+// WriteCheck writes regressed values and abnormal terminations of candidate to stdout,
+// and reports whether any of them is found.
+func (cli *CLI) WriteCheck(baseline, candidate []*mes.Record) (bool, error) {
+	regressions := FindRegressions(baseline, candidate)
+	abnormal := FindAbnormalTerminations(candidate)
+	if len(regressions) == 0 && len(abnormal) == 0 {
+		fmt.Fprintln(cli.errStream, "OK: No regression found")
+		return false, nil
+	}
+
+	var ds []*RecordData
+	for _, d := range regressions {
+		ds = append(ds, normalizeDifference(d))
+	}
+	termination := func(r *mes.Record) string { return r.Termination.Class }
+	for _, record := range abnormal {
+		ds = append(ds, diffRow("termination", "", uniqueText(baseline, termination), record.Termination.Class, "changed", opts.Out.Miss))
+	}
+	if err := cli.WriteData(ds); err != nil {
+		return true, err
+	}
+	for _, record := range abnormal {
+		fmt.Fprintf(cli.errStream, "FAIL: %s terminated as %s: %s\n", record.File, record.Termination.Class, record.Termination.Message)
+	}
+	fmt.Fprintf(cli.errStream, "FAIL: %d regression(s) and %d abnormal termination(s) found\n", len(regressions), len(abnormal))
+	return true, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"lsti/mes"
+)
+
+func TestIsRegression(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Difference
+		want bool
+	}{
+		{"increased", Difference{Baseline: 100, Candidate: 120, HasBaseline: true, HasCandidate: true}, true},
+		{"within threshold", Difference{Baseline: 100, Candidate: 105, HasBaseline: true, HasCandidate: true}, false},
+		{"decreased", Difference{Baseline: 100, Candidate: 70, HasBaseline: true, HasCandidate: true}, false},
+		{"missing in candidate", Difference{Baseline: 100, HasBaseline: true}, true},
+		{"missing in baseline", Difference{Candidate: 100, HasCandidate: true}, false},
+		{"cpuratio decreased", Difference{Metric: CpuRatio, Baseline: 4, Candidate: 3, HasBaseline: true, HasCandidate: true}, true},
+		{"cpuratio increased", Difference{Metric: CpuRatio, Baseline: 3, Candidate: 4, HasBaseline: true, HasCandidate: true}, false},
+		{"putil decreased", Difference{Metric: CoreUtilization, Baseline: 90, Candidate: 60, HasBaseline: true, HasCandidate: true}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.d.IsRegression(0, 10); got != tt.want {
+			t.Errorf("%s: IsRegression() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindRegressions(t *testing.T) {
+	opts.Out.Target = ClockSec
+	opts.Check.MaxRelative = 10
+	baseline := &mes.Record{ElapsedTime: 100, Termination: mes.Termination{Class: mes.NormalTermination}}
+	baseline.AddParent("Element processing", 90, 90, 90, 90)
+
+	// Crashed run without timing information nor elapsed time.
+	crashed := &mes.Record{Termination: mes.Termination{Class: mes.ErrorTermination}}
+	regressions := FindRegressions([]*mes.Record{baseline}, []*mes.Record{crashed})
+	if len(regressions) != 2 {
+		t.Errorf("FindRegressions() = %d regressions, want elapsed time and Element processing", len(regressions))
+	}
+	if abnormal := FindAbnormalTerminations([]*mes.Record{crashed}); len(abnormal) != 1 {
+		t.Errorf("FindAbnormalTerminations() = %d records, want 1", len(abnormal))
+	}
+
+	if regressions := FindRegressions([]*mes.Record{baseline}, []*mes.Record{baseline}); len(regressions) != 0 {
+		t.Errorf("FindRegressions() of the same record = %d regressions, want 0", len(regressions))
+	}
+}
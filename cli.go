@@ -4,14 +4,30 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/mattn/go-zglob"
+
+	"lsti/mes"
 )
 
 var opts struct {
 	Misc Misc   `group:"Miscellaneous"`
 	Out  Output `group:"Output control"`
+
+	Check       CheckCommand       `command:"check" description:"Check timings of candidate file(s) for regression against baseline file(s), and exit with code 2 if found"`
+	Cost        CostCommand        `command:"cost" description:"Show core-hours and cost of runs (number of CPUs x elapsed time)"`
+	Diff        DiffCommand        `command:"diff" description:"Compare timings and header fields of candidate file(s) with baseline file(s)"`
+	Imbalance   ImbalanceCommand   `command:"imbalance" description:"Show load imbalance among processors of MPP execution (max/mean, spread and slowest rank) of the totals and each timing category"`
+	Ingest      IngestCommand      `command:"ingest" description:"Store runs in SQLite database (tables runs, properties, timings and children), skipping files already ingested"`
+	Memory      MemoryCommand      `command:"memory" description:"Show memory of runs in words (requested, required, dynamically allocated and expanded)"`
+	Messages    MessagesCommand    `command:"messages" description:"Show warning and error messages of runs (code, count, first line and text)"`
+	Progress    ProgressCommand    `command:"progress" description:"Show history of cycle, time, time step and wall-clock rate from status lines of the solution"`
+	Scaling     ScalingCommand     `command:"scaling" description:"Show speedup, parallel efficiency and Amdahl's law serial fraction versus number of CPUs, or weak scaling efficiency"`
+	Sql         SqlCommand         `command:"sql" description:"Run SQL query on SQLite database written by \"ingest\" command, query is given as argument"`
+	Stats       StatsCommand       `command:"stats" description:"Show statistics of timings across files (count, min, max, mean, median, std and percentiles)"`
+	Termination TerminationCommand `command:"termination" description:"Show how runs terminated (normal, error, stopped, out of memory, negative volume, license failure or incomplete) with the message and cycle reached"`
 }
 
 type Misc struct {
@@ -20,40 +36,136 @@ type Misc struct {
 }
 
 type Output struct {
-	Abs      bool   `short:"a" long:"absolute" description:"Use absolute path for \"file\" property"`
-	Duration string `short:"d" long:"duration" description:"Duration format\nhuman-readable means [h]:mm:ss, and rounds down floating point values" choice:"human-readable" choice:"seconds" default:"human-readable"`
-	Miss     string `short:"m" long:"missing" description:"Replace missing values with specified string" default:"n/a"`
-	Output   string `short:"o" long:"output" description:"Output format\n(default: simple for single file, table for multiple files)" choice:"csv" choice:"html" choice:"json" choice:"simple" choice:"table" choice:"tsv"`
-	Query    string `short:"q" long:"query" description:"JMESPath query string\nSee http://jmespath.org/ for more information and examples"`
-	Relative string `short:"r" long:"relative" description:"Use relative path for \"file\" property (relative to specified path)\nIf \"-a, --absolute\" option is specified, this option will be ignored"`
-	Simple   bool   `short:"s" long:"simple" description:"Suppress detail timing information (e.g. Solids, Shells)"`
-	Target   string `short:"t" long:"target" description:"Target value used for statistics" choice:"cpusec" choice:"pcpu" choice:"clocksec" choice:"pclock" default:"clocksec"`
-	Verbose  []bool `short:"v" long:"verbose" description:"Output verbose information, this option can be specified multiple times\n-v:   + Output LS-DYNA module information and elapsed time\n-vv:  + Output execution environment\n-vvv: + Output more information"`
+	Abs          bool     `short:"a" long:"absolute" description:"Use absolute path for \"file\" property"`
+	Duration     string   `short:"d" long:"duration" description:"Duration format\nhuman-readable means [h]:mm:ss, and rounds down floating point values" choice:"human-readable" choice:"seconds" default:"human-readable"`
+	EndTime      float64  `long:"end-time" description:"Termination time of simulation to estimate completion with \"-f, --follow\"\n(default: termination time in the file if printed)"`
+	Follow       bool     `short:"f" long:"follow" description:"Follow a message file of running job and show progress (cycle, time, time step and estimated completion), then output timings when terminated"`
+	Jobs         int      `short:"j" long:"jobs" description:"Number of files parsed concurrently\n(default: number of CPUs)"`
+	Long         bool     `short:"l" long:"long" description:"Output a row for each timing and metric (file, segment, rank, parent, child, metric, value) in csv, json, jsonl, parquet or tsv format\nAll metrics and derived metrics of \"-t, --target\" are output in seconds or percent, and commands are not supported"`
+	MergeRanks   bool     `long:"merge-ranks" description:"Merge message files of ranks (mes0000, mes0001, ...) in the same directory into a run\nTimings of rank 0 are shown with number of rank files, missing ranks and imbalance among them"`
+	Miss         string   `short:"m" long:"missing" description:"Replace missing values with specified string" default:"n/a"`
+	Output       string   `short:"o" long:"output" description:"Output format\n(default: simple for single file, table for multiple files)" choice:"csv" choice:"html" choice:"json" choice:"jsonl" choice:"parquet" choice:"simple" choice:"table" choice:"tsv" choice:"xlsx"`
+	Query        string   `short:"q" long:"query" description:"JMESPath query string\nSee http://jmespath.org/ for more information and examples"`
+	Relative     string   `short:"r" long:"relative" description:"Use relative path for \"file\" property (relative to specified path)\nIf \"-a, --absolute\" option is specified, this option will be ignored"`
+	Segments     string   `long:"segments" description:"How to report multiple timing information blocks of restarted or multi-stage runs\nsum adds up timings of blocks, each reports each block as a record" choice:"sum" choice:"each" default:"sum"`
+	Simple       bool     `short:"s" long:"simple" description:"Suppress detail timing information (e.g. Solids, Shells)"`
+	Strict       bool     `long:"strict" description:"Exit with error if any problem is found while parsing files"`
+	Target       string   `short:"t" long:"target" description:"Target value (cpusec, pcpu, clocksec or pclock) used for timings and statistics\nComma-separated list (e.g. cpusec,clocksec) or all outputs a column for each target\nDerived metrics: cpuratio (CPU/wall-clock), putil (core utilization in percent, SMP only)\nand uselemcycle (microseconds per element per cycle, requires d3hsp)" default:"clocksec"`
+	Terminations []string `long:"termination" description:"Only include runs of this termination class, this option can be specified multiple times" choice:"normal" choice:"error" choice:"stopped" choice:"outOfMemory" choice:"negativeVolume" choice:"licenseFailure" choice:"incomplete"`
+	Verbose      []bool   `short:"v" long:"verbose" description:"Output verbose information, this option can be specified multiple times\n-v:   + Output LS-DYNA module information and elapsed time\n-vv:  + Output execution environment\n-vvv: + Output more information"`
+}
+
+// CheckCommand is the "check" command.
+type CheckCommand struct {
+	AllowAbnormal bool     `long:"allow-abnormal" description:"Do not fail candidate runs that are not normally terminated (e.g. error termination or incomplete)\nTimings of baseline and elapsed time missing in candidate are failures regardless"`
+	Baseline      []string `short:"b" long:"baseline" description:"Baseline file path or glob pattern, this option can be specified multiple times\nValues of multiple files are averaged" required:"true"`
+	MaxAbsolute   float64  `long:"max-absolute" description:"Allowed increase in the unit of \"-t, --target\" (seconds or percentage points), or decrease of cpuratio and putil\nElapsed time is always compared in seconds" default:"0"`
+	MaxRelative   float64  `long:"max-relative" description:"Allowed increase in percent, or decrease of cpuratio and putil" default:"10"`
+}
+
+// CostCommand is the "cost" command.
+type CostCommand struct {
+	Config  string `short:"c" long:"config" description:"JSON file that configures price per core-hour, license token model and tags\nSee CostConfig in cost.go for the format"`
+	GroupBy string `short:"g" long:"group-by" description:"Show totals grouped by this property instead of each run\n\"tag\" is defined in the configuration file" choice:"dir" choice:"hostname" choice:"inputFile" choice:"platform" choice:"version" choice:"tag"`
+}
+
+// DiffCommand is the "diff" command.
+type DiffCommand struct {
+	Baseline []string `short:"b" long:"baseline" description:"Baseline file path or glob pattern, this option can be specified multiple times\nValues of multiple files are averaged" required:"true"`
+}
+
+// ImbalanceCommand is the "imbalance" command.
+type ImbalanceCommand struct{}
+
+// IngestCommand is the "ingest" command.
+type IngestCommand struct {
+	Database string `long:"db" description:"SQLite database file, created if not exists" default:"lsti.db"`
+}
+
+// MemoryCommand is the "memory" command.
+type MemoryCommand struct{}
+
+// MessagesCommand is the "messages" command.
+type MessagesCommand struct {
+	Severity string `long:"severity" description:"Only show messages of this severity" choice:"warning" choice:"error"`
+}
+
+// ProgressCommand is the "progress" command.
+type ProgressCommand struct{}
+
+// ScalingCommand is the "scaling" command.
+type ScalingCommand struct {
+	GroupBy string `short:"g" long:"group-by" description:"Group runs by this property" choice:"dir" choice:"hostname" choice:"inputFile" choice:"platform" choice:"version" default:"inputFile"`
+	Weak    bool   `long:"weak" description:"Weak scaling: problem size grows with number of CPUs in each group (e.g. \"-g dir\")\nEfficiency is time of the smallest number of CPUs divided by time, without speedup and serial fraction"`
+}
+
+// SqlCommand is the "sql" command.
+type SqlCommand struct {
+	Database string `long:"db" description:"SQLite database file written by \"ingest\" command, which must exist" default:"lsti.db"`
+}
+
+// StatsCommand is the "stats" command.
+type StatsCommand struct {
+	Percentiles []float64 `short:"p" long:"percentile" description:"Percentile to report, this option can be specified multiple times" default:"5" default:"25" default:"75" default:"95"`
+}
+
+// TerminationCommand is the "termination" command.
+type TerminationCommand struct {
+	Count bool `short:"c" long:"count" description:"Show number of runs for each termination class instead of each run"`
 }
 
 // CLI is the command line object.
 type CLI struct {
+	// inStream is the stdin to read message file content from
+	// if "-" is specified as file path.
+	inStream io.Reader
+
 	// outStream and errStream are the stdout and stderr
 	// to write message from the CLI.
 	outStream, errStream io.Writer
+
+	// parse parses a file, archive member or stdin, or ParseMessageFile if nil.
+	parse func(src *source) (*mes.Record, error)
 }
 
 // Run invokes the CLI with the given arguments.
 func (cli *CLI) Run(args []string) int {
 	parser := flags.NewParser(&opts, flags.PrintErrors|flags.PassDoubleDash)
 	parser.Name = Name
-	parser.Usage = `[OPTIONS] [FILE]...
-
-lsti extracts timing information from LS-DYNA message file(s) (e.g. messag, mes****),
-and display results in the specified format
-File path accepts Unix style glob pattern (e.g. mes*, ./**/messag)
+	parser.SubcommandsOptional = true
+	parser.Usage = "[OPTIONS] [FILE]..."
+	parser.LongDescription = `lsti extracts timing information from LS-DYNA message file(s)
+(e.g. messag, mes****), and display results in the specified format
+File path accepts Unix style glob pattern (e.g. mes*, ./**/messag),
+and "-" (or no file with piped input) reads stdin
+Compressed files (.gz, .bz2, .xz, .zst) and tar/zip archives are read,
+and archive members are selected by "!" (e.g. runs.tar.gz!run*/mes*)
 
 Example:
-  lsti mes0000
-  lsti ./**/mes* -o csv > timings.csv
-  lsti ./**/mes* -o table > timings.md
-  lsti ./**/messag -vvv --query "[].{properties:properties[?name=='file' || name=='elapsedTime']}"
-  `
+$ lsti mes0000
+$ lsti ./**/mes* -o csv > timings.csv
+$ lsti ./**/mes* -t cpusec,clocksec -o table
+$ lsti ./**/d3hsp -v -t cpuratio,putil,uselemcycle
+$ lsti runs.tar.gz "runs.zip!run1/mes*" messag.gz
+$ ssh node cat mes0000 | lsti -
+$ lsti -f mes0000
+$ lsti ./**/mes* -o table > timings.md
+$ lsti ./**/mes* --merge-ranks -o csv
+$ lsti ./**/mes* -o xlsx > timings.xlsx
+$ lsti ./**/mes* -l -o parquet > timings.parquet
+$ lsti ./**/messag -v -o json -q "[].properties[?name=='elapsedTime'].value"
+$ lsti stats ./**/mes* -t cpusec
+$ lsti diff -b "R9/**/mes*" "R11/**/mes*"
+$ lsti check -b baseline/messag --max-relative 5 messag
+$ lsti imbalance ./**/mes0000
+$ lsti memory ./**/d3hsp -o csv
+$ lsti messages ./**/messag --severity warning
+$ lsti progress mes0000 -o csv -d seconds > progress.csv
+$ lsti scaling ./**/messag -o json
+$ lsti termination ./**/messag --termination error --termination incomplete
+$ lsti cost ./**/mes0000 -c cost.json -g tag
+$ lsti ingest ./**/mes* --db runs.db
+$ lsti sql --db runs.db "SELECT file, value FROM runs JOIN properties ON id = run_id WHERE name = 'elapsedTime'"`
 
 	arguments, err := parser.Parse()
 	if err != nil {
@@ -73,36 +185,182 @@ Example:
 		return ExitCodeOK
 	}
 
-	// If arguments' length is zero, show help and exit with error.
-	if len(arguments) == 0 {
-		parser.WriteHelp(os.Stdout)
+	var command string
+	if parser.Active != nil {
+		command = parser.Active.Name
+	}
+
+	// Long format has raw values of timings of files, not results of command.
+	if command != "" && opts.Out.Long {
+		fmt.Fprintln(cli.errStream, "\"-l, --long\" option cannot be used with command")
 		return ExitCodeError
 	}
 
-	// Expand glob pattern.
-	var files []string
-	for _, pattern := range arguments {
-		matches, err := zglob.Glob(pattern)
-		if err != nil {
-			fmt.Fprintf(cli.errStream, "Invalid file path or glob pattern: %s\n", pattern)
+	// Query database with SQL given as arguments instead of files.
+	if command == "sql" {
+		if len(arguments) != 1 {
+			fmt.Fprintln(cli.errStream, "\"sql\" command requires a query as argument")
+			return ExitCodeError
 		}
-		files = append(files, matches...)
+		if err := cli.WriteSql(arguments[0]); err != nil {
+			fmt.Fprintln(cli.errStream, err)
+			return ExitCodeError
+		}
+		return ExitCodeOK
 	}
 
-	// If no files found, return error code and exit.
-	if len(files) == 0 {
-		fmt.Fprintf(cli.errStream, "No files found matching: %s\n", arguments)
+	if _, err := parseTargets(opts.Out.Target); err != nil {
+		fmt.Fprintln(cli.errStream, err)
+		return ExitCodeError
+	}
+
+	// If arguments' length is zero, read stdin if piped, otherwise show help and exit with error.
+	if len(arguments) == 0 {
+		if !isPiped(cli.inStream) {
+			parser.WriteHelp(os.Stdout)
+			return ExitCodeError
+		}
+		arguments = []string{stdinName}
+	}
+
+	// Expand glob pattern.
+	files, err := cli.ExpandPatterns(arguments)
+	if err != nil {
+		fmt.Fprintln(cli.errStream, err)
 		return ExitCodeError
 	}
 
+	// Follow a running job, and output parsed data when terminated.
+	if opts.Out.Follow {
+		if command != "" || len(files) != 1 {
+			fmt.Fprintln(cli.errStream, "\"-f, --follow\" option requires a single file and no command")
+			return ExitCodeError
+		}
+		if err := cli.Follow(files[0]); err != nil {
+			fmt.Fprintln(cli.errStream, err)
+			return ExitCodeError
+		}
+		return ExitCodeOK
+	}
+
+	// Without command, output parsed data in specified format as files are parsed.
+	if command == "" {
+		if err := cli.WriteFiles(files); err != nil {
+			fmt.Fprintln(cli.errStream, err)
+			return ExitCodeError
+		}
+		return ExitCodeOK
+	}
+
+	// Ingest parses only files not ingested yet.
+	if command == "ingest" {
+		if err := cli.WriteIngest(files); err != nil {
+			fmt.Fprintln(cli.errStream, err)
+			return ExitCodeError
+		}
+		return ExitCodeOK
+	}
+
 	// Parse files.
-	records, _ := cli.ParseMessageFiles(files)
+	records, err := cli.ParseFiles(files)
+	if err != nil {
+		fmt.Fprintln(cli.errStream, err)
+		return ExitCodeError
+	}
 
-	// Output parsed data in specified format.
-	if err := cli.Write(records); err != nil {
+	// Output results of command in specified format.
+	switch command {
+	case "check":
+		var baseline []*mes.Record
+		baseline, err = cli.ParsePatterns(opts.Check.Baseline)
+		if err == nil {
+			var regressed bool
+			regressed, err = cli.WriteCheck(baseline, records)
+			if err == nil && regressed {
+				return ExitCodeRegression
+			}
+		}
+	case "cost":
+		err = cli.WriteCost(records)
+	case "diff":
+		var baseline []*mes.Record
+		baseline, err = cli.ParsePatterns(opts.Diff.Baseline)
+		if err == nil {
+			err = cli.WriteDiff(baseline, records)
+		}
+	case "imbalance":
+		err = cli.WriteImbalance(records)
+	case "memory":
+		err = cli.WriteMemory(records)
+	case "messages":
+		err = cli.WriteMessages(records)
+	case "progress":
+		err = cli.WriteProgress(records)
+	case "scaling":
+		err = cli.WriteScaling(records)
+	case "stats":
+		err = cli.WriteStats(records)
+	case "termination":
+		err = cli.WriteTermination(records)
+	}
+	if err != nil {
 		fmt.Fprintln(cli.errStream, err)
 		return ExitCodeError
 	}
 
 	return ExitCodeOK
 }
+
+// ExpandPatterns expands glob patterns and returns sorted file paths.
+// Pattern like "runs.tar.gz!run*/mes*" expands archive part, and member part is kept.
+// It returns error if no files found.
+func (cli *CLI) ExpandPatterns(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		if pattern == stdinName {
+			files = append(files, stdinName)
+			continue
+		}
+		archive, member, ok := splitArchivePath(pattern)
+		matches, err := zglob.Glob(archive)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Invalid file path or glob pattern: %s\n", pattern)
+		}
+		for _, match := range matches {
+			if ok {
+				match += archiveSeparator + member
+			}
+			files = append(files, match)
+		}
+	}
+
+	// If no files found, return error.
+	if len(files) == 0 {
+		return nil, fmt.Errorf("No files found matching: %s", patterns)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// ParsePatterns expands glob patterns and parses matched files.
+func (cli *CLI) ParsePatterns(patterns []string) ([]*mes.Record, error) {
+	files, err := cli.ExpandPatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return cli.ParseFiles(files)
+}
+
+// ParseFiles parses files and reports problems found in them.
+// If "--strict" is specified, it returns error if any problem is found.
+func (cli *CLI) ParseFiles(files []string) ([]*mes.Record, error) {
+	records, diagnostics := cli.ParseMessageFiles(files)
+	for _, d := range diagnostics {
+		fmt.Fprintln(cli.errStream, d)
+	}
+	if opts.Out.Strict && len(diagnostics) > 0 {
+		return nil, fmt.Errorf("%d problem(s) found while parsing files", len(diagnostics))
+	}
+	return records, nil
+}
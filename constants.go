@@ -1,5 +1,7 @@
 package main
 
+import "lsti/mes"
+
 // Name and Version are showed in help message and version message.
 const (
 	Name    = "lsti"
@@ -10,6 +12,7 @@ const (
 const (
 	ExitCodeOK = iota
 	ExitCodeError
+	ExitCodeRegression
 )
 
 // Option strings are string values that represent input string sets of limited values.
@@ -19,16 +22,28 @@ const (
 	Seconds = "seconds"
 
 	// (-f, --format) option
-	Csv    = "csv"
-	Html   = "html"
-	Json   = "json"
-	Simple = "simple"
-	Table  = "table"
-	Tsv    = "tsv"
+	Csv     = "csv"
+	Html    = "html"
+	Json    = "json"
+	Jsonl   = "jsonl"
+	Parquet = "parquet"
+	Simple  = "simple"
+	Table   = "table"
+	Tsv     = "tsv"
+	Xlsx    = "xlsx"
+
+	// (--segments) option
+	SumSegments  = "sum"
+	EachSegments = "each"
 
 	// (-t, --target) option
-	CpuSec       = "cpusec"
-	CpuPercent   = "pcpu"
-	ClockSec     = "clocksec"
-	ClockPercent = "pclock"
+	CpuSec       = mes.CpuSec
+	CpuPercent   = mes.CpuPercent
+	ClockSec     = mes.ClockSec
+	ClockPercent = mes.ClockPercent
+
+	// (-t, --target) option of derived metrics
+	CpuRatio        = mes.CpuRatio
+	CoreUtilization = mes.CoreUtilization
+	ElementCycle    = mes.ElementCycle
 )
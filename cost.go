@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/mattn/go-zglob"
+
+	"lsti/mes"
+)
+
+// CostConfig is the configuration of "cost" command loaded from json file.
+//
+// Example:
+//   {
+//     "pricePerCoreHour": 0.05,
+//     "license": {"tokensPerRun": 5, "tokensPerCpu": 1, "pricePerTokenHour": 0.2},
+//     "tags": [{"name": "projectA", "patterns": ["/share/projectA/**/*"]}]
+//   }
+type CostConfig struct {
+	PricePerCoreHour float64       `json:"pricePerCoreHour"`
+	License          *LicenseModel `json:"license"`
+	Tags             []*Tag        `json:"tags"`
+}
+
+// LicenseModel represents license tokens checked out by a run.
+type LicenseModel struct {
+	TokensPerRun      float64 `json:"tokensPerRun"`
+	TokensPerCpu      float64 `json:"tokensPerCpu"`
+	PricePerTokenHour float64 `json:"pricePerTokenHour"`
+}
+
+// Tag is a user-defined name for runs whose file path matches any of glob patterns.
+type Tag struct {
+	Name     string   `json:"name"`
+	Patterns []string `json:"patterns"`
+}
+
+// LoadCostConfig loads configuration of "cost" command from json file.
+func LoadCostConfig(file string) (*CostConfig, error) {
+	data, err := ioutil.ReadFile(filepath.FromSlash(file))
+	if err != nil {
+		return nil, err
+	}
+	var config CostConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// TagOf returns name of the first tag that matches file path of record, or "untagged".
+func (config *CostConfig) TagOf(record *mes.Record) string {
+	for _, tag := range config.Tags {
+		for _, pattern := range tag.Patterns {
+			if matched, _ := zglob.Match(pattern, filepath.ToSlash(record.File)); matched {
+				return tag.Name
+			}
+		}
+	}
+	return "untagged"
+}
+
+// A Cost represents core-hours, license token-hours and their price.
+type Cost struct {
+	Runs                  int
+	CoreHours, TokenHours float64
+	Price                 float64
+}
+
+// Add adds cost of record to cost.
+func (cost *Cost) Add(record *mes.Record, config *CostConfig) {
+	hours := record.ElapsedTime / 3600
+	coreHours := float64(record.NumCpus) * hours
+	cost.Runs++
+	cost.CoreHours += coreHours
+	cost.Price += coreHours * config.PricePerCoreHour
+	if license := config.License; license != nil {
+		tokenHours := (license.TokensPerRun + license.TokensPerCpu*float64(record.NumCpus)) * hours
+		cost.TokenHours += tokenHours
+		cost.Price += tokenHours * license.PricePerTokenHour
+	}
+}
+
+// WriteCost writes core-hours and cost of records to stdout.
+func (cli *CLI) WriteCost(records []*mes.Record) error {
+	config := &CostConfig{}
+	if opts.Cost.Config != "" {
+		var err error
+		config, err = LoadCostConfig(opts.Cost.Config)
+		if err != nil {
+			return err
+		}
+	}
+	return cli.WriteData(cli.NormalizeCost(records, config))
+}
+
+// costRuns returns records counting each run once. Rank files of an MPP run (mes0000, mes0001, ...)
+// in the same directory are merged as "--merge-ranks" does, since each of them has number of CPUs
+// and elapsed time of the whole run.
+func costRuns(records []*mes.Record) []*mes.Record {
+	var runs []*mes.Record
+	groups := make(map[string][]*mes.Record)
+	var keys []string
+	for _, record := range records {
+		if _, ok := mes.RankFileNumber(record.File); !ok || record.RankFiles != nil {
+			runs = append(runs, record)
+			continue
+		}
+		key := fmt.Sprintf("%s#%d", filepath.Dir(record.File), record.Segment)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+			// Placeholder replaced by the merged record in order of the first rank file.
+			runs = append(runs, nil)
+		}
+		groups[key] = append(groups[key], record)
+	}
+	i := 0
+	for j, run := range runs {
+		if run == nil {
+			runs[j] = mes.MergeRankFiles(groups[keys[i]])
+			i++
+		}
+	}
+	return runs
+}
+
+// NormalizeCost normalizes core-hours and cost of records for json output.
+// If "-g, --group-by" option is specified, each group becomes a record,
+// otherwise each run becomes a record. The last record is the total.
+// Rank files of an MPP run are counted as a run (see costRuns).
+func (cli *CLI) NormalizeCost(records []*mes.Record, config *CostConfig) []*RecordData {
+	records = costRuns(records)
+	var ds []*RecordData
+	row := func(key, name string, cost *Cost, extra ...*JsonData) {
+		properties := []*JsonData{{Name: key, Value: name}}
+		properties = append(properties, extra...)
+		properties = append(properties,
+			&JsonData{Name: "runs", Value: cost.Runs},
+			&JsonData{Name: "coreHours", Value: roundTo(cost.CoreHours, 4)},
+		)
+		if config.License != nil {
+			properties = append(properties, &JsonData{Name: "tokenHours", Value: roundTo(cost.TokenHours, 4)})
+		}
+		if config.PricePerCoreHour != 0 || config.License != nil {
+			properties = append(properties, &JsonData{Name: "cost", Value: roundTo(cost.Price, 2)})
+		}
+		ds = append(ds, &RecordData{Properties: properties, Timings: make([]*TimingData, 0)})
+	}
+
+	total := &Cost{}
+	for _, record := range records {
+		total.Add(record, config)
+	}
+
+	groupBy := opts.Cost.GroupBy
+	if groupBy == "" {
+		for _, record := range records {
+			cost := &Cost{}
+			cost.Add(record, config)
+			row("file", record.File, cost,
+				&JsonData{Name: "numCpus", Value: record.NumCpus},
+				&JsonData{Name: "elapsedTime", Value: formatValue(record.ElapsedTime, ClockSec)},
+			)
+		}
+		row("file", "total", total,
+			&JsonData{Name: "numCpus", Value: opts.Out.Miss},
+			&JsonData{Name: "elapsedTime", Value: opts.Out.Miss},
+		)
+		return ds
+	}
+
+	key := groupFields[groupBy]
+	if groupBy == "tag" {
+		key = config.TagOf
+	}
+	for _, group := range groupRecords(records, key) {
+		cost := &Cost{}
+		for _, record := range group.Records {
+			cost.Add(record, config)
+		}
+		row(groupBy, group.Key, cost)
+	}
+	row(groupBy, "total", total)
+	return ds
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"lsti/mes"
+)
+
+func TestNormalizeCostRankFiles(t *testing.T) {
+	opts.Cost.GroupBy = ""
+	var records []*mes.Record
+	for _, file := range []string{"run1/mes0000", "run1/mes0001", "run2/mes0000", "run3/messag"} {
+		records = append(records, &mes.Record{File: file, NumCpus: 2, ElapsedTime: 3600})
+	}
+	ds := (&CLI{}).NormalizeCost(records, &CostConfig{})
+
+	// A row for each run and the total.
+	want := []struct {
+		file      string
+		coreHours float64
+	}{
+		{"run1/mes0000", 2},
+		{"run2/mes0000", 2},
+		{"run3/messag", 2},
+		{"total", 6},
+	}
+	if len(ds) != len(want) {
+		t.Fatalf("len(rows) = %d, want %d", len(ds), len(want))
+	}
+	for i, w := range want {
+		var file interface{}
+		var coreHours interface{}
+		for _, p := range ds[i].Properties {
+			switch p.Name {
+			case "file":
+				file = p.Value
+			case "coreHours":
+				coreHours = p.Value
+			}
+		}
+		if file != w.file || coreHours != w.coreHours {
+			t.Errorf("rows[%d] = %v of %v core-hours, want %s of %v", i, file, coreHours, w.file, w.coreHours)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"lsti/mes"
+)
+
+// databaseSchema creates tables of database written by "ingest" command.
+// Runs are keyed by absolute file path, SHA-256 of the content and segment (0 unless
+// "--segments" option is specified), and values of properties are stored in their types
+// (text, integer or real). The file column is the path as shown in output.
+const databaseSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY,
+	path TEXT NOT NULL,
+	file TEXT NOT NULL,
+	hash TEXT NOT NULL,
+	segment INTEGER NOT NULL,
+	ingested_at TEXT NOT NULL,
+	UNIQUE (path, hash, segment)
+);
+CREATE TABLE IF NOT EXISTS properties (
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	name TEXT NOT NULL,
+	value,
+	PRIMARY KEY (run_id, name)
+);
+CREATE TABLE IF NOT EXISTS timings (
+	id INTEGER PRIMARY KEY,
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	cpu_sec REAL,
+	cpu_percent REAL,
+	clock_sec REAL,
+	clock_percent REAL
+);
+CREATE TABLE IF NOT EXISTS children (
+	timing_id INTEGER NOT NULL REFERENCES timings (id),
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	cpu_sec REAL,
+	cpu_percent REAL,
+	clock_sec REAL,
+	clock_percent REAL
+);
+CREATE INDEX IF NOT EXISTS timings_run_id ON timings (run_id);
+CREATE INDEX IF NOT EXISTS children_timing_id ON children (timing_id);
+`
+
+// openDatabase opens SQLite database file, and creates tables if not exist.
+func openDatabase(name string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(databaseSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// recordProperties returns header and footer information of record stored in properties table.
+func recordProperties(record *mes.Record) []*JsonData {
+	properties := []*JsonData{
+		{Name: "fileType", Value: record.FileType},
+		{Name: "version", Value: record.Version},
+		{Name: "revision", Value: record.Revision},
+		{Name: "date", Value: record.Date},
+		{Name: "time", Value: record.Time},
+		{Name: "licensedTo", Value: record.LicensedTo},
+		{Name: "issuedBy", Value: record.IssuedBy},
+		{Name: "platform", Value: record.Platform},
+		{Name: "os", Value: record.Os},
+		{Name: "compiler", Value: record.Compiler},
+		{Name: "hostname", Value: record.Hostname},
+		{Name: "precision", Value: record.Precision},
+		{Name: "svnVersion", Value: record.SvnVersion},
+		{Name: "inputFile", Value: record.InputFile},
+		{Name: "numCpus", Value: record.NumCpus},
+		{Name: "normalTermination", Value: record.NormalTermination},
+		{Name: "elapsedTime", Value: record.ElapsedTime},
+		{Name: "termination", Value: record.Termination.Class},
+		{Name: "terminationMessage", Value: record.Termination.Message},
+		{Name: "cycle", Value: record.Termination.Cycle},
+		{Name: "problemTime", Value: record.Termination.Time},
+		{Name: "numWarnings", Value: record.GetNumMessages(mes.Warning)},
+		{Name: "numErrors", Value: record.GetNumMessages(mes.Error)},
+		{Name: "memoryRequested", Value: record.Memory.Requested},
+		{Name: "memoryRequired", Value: record.Memory.Required},
+	}
+	if record.FileType == mes.D3hspFile {
+		properties = append(properties,
+			&JsonData{Name: "numNodes", Value: record.Model.NumNodes},
+			&JsonData{Name: "numParts", Value: record.Model.NumParts},
+			&JsonData{Name: "numSolids", Value: record.Model.NumSolids},
+			&JsonData{Name: "numShells", Value: record.Model.NumShells},
+			&JsonData{Name: "numThickShells", Value: record.Model.NumThickShells},
+			&JsonData{Name: "numBeams", Value: record.Model.NumBeams},
+			&JsonData{Name: "terminationTime", Value: record.Control.TerminationTime},
+			&JsonData{Name: "addedMassRatio", Value: record.MassScaling.Ratio},
+		)
+	}
+	return properties
+}
+
+// ingestRecord inserts record into database in a transaction.
+// It reports false if the record of the same absolute file path, content hash and segment
+// is already ingested.
+func ingestRecord(db *sql.DB, record *mes.Record) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT OR IGNORE INTO runs (path, file, hash, segment, ingested_at) VALUES (?, ?, ?, ?, ?)",
+		record.Path, record.File, record.Hash, record.Segment, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	runID, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range recordProperties(record) {
+		if _, err := tx.Exec("INSERT INTO properties (run_id, name, value) VALUES (?, ?, ?)", runID, p.Name, p.Value); err != nil {
+			return false, err
+		}
+	}
+	for i, parent := range record.Parents {
+		result, err := tx.Exec("INSERT INTO timings (run_id, position, name, cpu_sec, cpu_percent, clock_sec, clock_percent) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			runID, i, parent.Name, parent.CpuSec, parent.CpuPercent, parent.ClockSec, parent.ClockPercent)
+		if err != nil {
+			return false, err
+		}
+		timingID, err := result.LastInsertId()
+		if err != nil {
+			return false, err
+		}
+		for j, child := range parent.Children {
+			if _, err := tx.Exec("INSERT INTO children (timing_id, position, name, cpu_sec, cpu_percent, clock_sec, clock_percent) VALUES (?, ?, ?, ?, ?, ?, ?)",
+				timingID, j, child.Name, child.CpuSec, child.CpuPercent, child.ClockSec, child.ClockPercent); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, tx.Commit()
+}
+
+// WriteIngest ingests files into database of "--db" option,
+// and writes whether each record is ingested or skipped as already ingested.
+// Content of each file is hashed before parsing, and files of the same absolute path
+// and content hash as ingested runs are not parsed.
+func (cli *CLI) WriteIngest(files []string) error {
+	db, err := openDatabase(opts.Ingest.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Records of files already ingested have only File, Path and Hash.
+	var mu sync.Mutex
+	skipped := make(map[string]bool)
+	key := func(path, hash string) string { return path + archiveSeparator + hash }
+	cli.parse = func(src *source) (*mes.Record, error) {
+		r, err := src.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		path, hash := mes.AbsPath(src.Name), hex.EncodeToString(sum[:])
+		var id int64
+		err = db.QueryRow("SELECT id FROM runs WHERE path = ? AND hash = ? LIMIT 1", path, hash).Scan(&id)
+		switch {
+		case err == sql.ErrNoRows:
+			return mes.ParseReader(bytes.NewReader(data), src.Name, sourceOptions(src))
+		case err != nil:
+			return nil, err
+		}
+		mu.Lock()
+		skipped[key(path, hash)] = true
+		mu.Unlock()
+		return &mes.Record{File: mes.TranslatePath(src.Name, sourceOptions(src)), Path: path, Hash: hash}, nil
+	}
+	defer func() { cli.parse = nil }()
+	records, err := cli.ParseFiles(files)
+	if err != nil {
+		return err
+	}
+
+	ds := make([]*RecordData, len(records))
+	ingested := 0
+	for i, record := range records {
+		ok := false
+		if !skipped[key(record.Path, record.Hash)] {
+			if ok, err = ingestRecord(db, record); err != nil {
+				return fmt.Errorf("%s: %v", record.File, err)
+			}
+		}
+		status := "skipped"
+		if ok {
+			status = "ingested"
+			ingested++
+		}
+		properties := []*JsonData{{Name: "file", Value: record.File}}
+		if record.Segment > 0 {
+			properties = append(properties, &JsonData{Name: "segment", Value: record.Segment})
+		}
+		properties = append(properties,
+			&JsonData{Name: "hash", Value: record.Hash},
+			&JsonData{Name: "status", Value: status},
+		)
+		ds[i] = &RecordData{Properties: properties, Timings: make([]*TimingData, 0)}
+	}
+	fmt.Fprintf(cli.errStream, "%d run(s) ingested, %d run(s) skipped as already ingested\n", ingested, len(records)-ingested)
+	return cli.WriteData(ds)
+}
+
+// WriteSql executes SQL query on database of "--db" option, and writes result rows to stdout.
+// Unlike "ingest" command, the database is not created if not exists.
+func (cli *CLI) WriteSql(query string) error {
+	if _, err := os.Stat(opts.Sql.Database); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", opts.Sql.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	var ds []*RecordData
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		properties := make([]*JsonData, len(columns))
+		for i, column := range columns {
+			value := values[i]
+			switch v := value.(type) {
+			case []byte:
+				value = string(v)
+			case nil:
+				value = opts.Out.Miss
+			}
+			properties[i] = &JsonData{Name: column, Value: value}
+		}
+		ds = append(ds, &RecordData{Properties: properties, Timings: make([]*TimingData, 0)})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return cli.WriteData(ds)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteIngestSkipsWithoutParsing(t *testing.T) {
+	opts.Ingest.Database = filepath.Join(t.TempDir(), "runs.db")
+	opts.Out.Output = Csv
+	defer func() { opts.Out.Output = "" }()
+	// Parsing the file reports problems of rows with values that are not numbers.
+	files := []string{"mes/testdata/bad_numbers_messag"}
+
+	for i, want := range []struct {
+		status   string
+		problems bool
+	}{{"ingested", true}, {"skipped", false}} {
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		cli := &CLI{outStream: out, errStream: errOut}
+		if err := cli.WriteIngest(files); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), ","+want.status) {
+			t.Errorf("ingest %d: output = %q, want %s", i+1, out, want.status)
+		}
+		if problems := strings.Contains(errOut.String(), "bad_numbers_messag:"); problems != want.problems {
+			t.Errorf("ingest %d: problems reported = %v, want %v:\n%s", i+1, problems, want.problems, errOut)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"lsti/mes"
+)
+
+// A Difference represents a numeric value compared between baseline and candidate.
+type Difference struct {
+	Name, Metric              string
+	Baseline, Candidate       float64
+	HasBaseline, HasCandidate bool
+}
+
+// Change returns absolute change from baseline to candidate.
+func (d *Difference) Change() float64 {
+	return d.Candidate - d.Baseline
+}
+
+// RelativeChange returns relative change from baseline to candidate in percent.
+// It returns NaN if baseline is zero.
+func (d *Difference) RelativeChange() float64 {
+	if d.Baseline == 0 {
+		return math.NaN()
+	}
+	return (d.Candidate - d.Baseline) / d.Baseline * 100
+}
+
+// Comparable reports whether both baseline and candidate values exist.
+func (d *Difference) Comparable() bool {
+	return d.HasBaseline && d.HasCandidate
+}
+
+// timingName returns name of timing row, child name is joined to parent name by "/".
+func timingName(parent, child string) string {
+	if child == "" {
+		return parent
+	}
+	return parent + "/" + child
+}
+
+// meanTimings returns names of timing rows in order of appearance and their mean values of dataType.
+func meanTimings(records []*mes.Record, dataType string) ([]string, map[string]float64) {
+	var names []string
+	means := make(map[string]float64)
+	add := func(name string, values []float64) {
+		// Derived metric not available in any record is missing.
+		if len(values) == 0 {
+			return
+		}
+		names = append(names, name)
+		means[name] = ComputeStatistics(values, nil).Mean
+	}
+	for _, p := range collectSeries(records, dataType) {
+		add(timingName(p.Name, ""), p.Values)
+		if opts.Out.Simple {
+			continue
+		}
+		for _, c := range p.Children {
+			add(timingName(p.Name, c.Name), c.Values)
+		}
+	}
+	return names, means
+}
+
+// meanOf returns mean value of f for records.
+func meanOf(records []*mes.Record, f func(*mes.Record) float64) float64 {
+	var values []float64
+	for _, record := range records {
+		values = append(values, f(record))
+	}
+	return ComputeStatistics(values, nil).Mean
+}
+
+// ComputeDifferences compares elapsed time, number of CPUs and timings of dataTypes
+// between baseline and candidate records. Values of multiple records are averaged.
+func ComputeDifferences(baseline, candidate []*mes.Record, dataTypes []string) []*Difference {
+	ds := []*Difference{
+		{
+			Name:         "elapsedTime",
+			Metric:       ClockSec,
+			Baseline:     meanOf(baseline, func(r *mes.Record) float64 { return r.ElapsedTime }),
+			Candidate:    meanOf(candidate, func(r *mes.Record) float64 { return r.ElapsedTime }),
+			HasBaseline:  len(baseline) > 0,
+			HasCandidate: len(candidate) > 0,
+		},
+		{
+			Name:         "numCpus",
+			Baseline:     meanOf(baseline, func(r *mes.Record) float64 { return float64(r.NumCpus) }),
+			Candidate:    meanOf(candidate, func(r *mes.Record) float64 { return float64(r.NumCpus) }),
+			HasBaseline:  len(baseline) > 0,
+			HasCandidate: len(candidate) > 0,
+		},
+	}
+
+	for _, dataType := range dataTypes {
+		baseNames, baseMeans := meanTimings(baseline, dataType)
+		candNames, candMeans := meanTimings(candidate, dataType)
+
+		// Keep baseline order, and append rows found only in candidate.
+		names := baseNames
+		for _, name := range candNames {
+			if _, ok := baseMeans[name]; !ok {
+				names = append(names, name)
+			}
+		}
+
+		for _, name := range names {
+			d := Difference{Name: name, Metric: dataType}
+			d.Baseline, d.HasBaseline = baseMeans[name]
+			d.Candidate, d.HasCandidate = candMeans[name]
+			ds = append(ds, &d)
+		}
+	}
+	return ds
+}
+
+// diffFields are header fields compared as text by "diff" command.
+var diffFields = []struct {
+	Name string
+	Get  func(*mes.Record) string
+}{
+	{"version", func(r *mes.Record) string { return r.Version }},
+	{"revision", func(r *mes.Record) string { return fmt.Sprint(r.Revision) }},
+	{"svnVersion", func(r *mes.Record) string { return fmt.Sprint(r.SvnVersion) }},
+	{"precision", func(r *mes.Record) string { return r.Precision }},
+	{"platform", func(r *mes.Record) string { return r.Platform }},
+	{"os", func(r *mes.Record) string { return r.Os }},
+	{"compiler", func(r *mes.Record) string { return r.Compiler }},
+	{"hostname", func(r *mes.Record) string { return r.Hostname }},
+	{"inputFile", func(r *mes.Record) string { return r.InputFile }},
+}
+
+// uniqueText returns sorted unique values of f for records joined by ", ".
+func uniqueText(records []*mes.Record, f func(*mes.Record) string) string {
+	found := make(map[string]bool)
+	var values []string
+	for _, record := range records {
+		v := f(record)
+		if !found[v] {
+			found[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return strings.Join(values, ", ")
+}
+
+// WriteDiff writes differences between baseline and candidate records to stdout.
+func (cli *CLI) WriteDiff(baseline, candidate []*mes.Record) error {
+	return cli.WriteData(cli.NormalizeDiff(baseline, candidate))
+}
+
+// NormalizeDiff normalizes differences between baseline and candidate records for json output.
+// Each compared value becomes a record that has only properties.
+func (cli *CLI) NormalizeDiff(baseline, candidate []*mes.Record) []*RecordData {
+	naWord := opts.Out.Miss
+	ds := []*RecordData{diffRow("files", "", len(baseline), len(candidate), len(candidate)-len(baseline), naWord)}
+	for _, field := range diffFields {
+		base := uniqueText(baseline, field.Get)
+		cand := uniqueText(candidate, field.Get)
+		change := ""
+		if base != cand {
+			change = "changed"
+		}
+		ds = append(ds, diffRow(field.Name, "", base, cand, change, naWord))
+	}
+
+	metrics := []string{CpuSec, CpuPercent, ClockSec, ClockPercent}
+	for _, d := range ComputeDifferences(baseline, candidate, metrics) {
+		ds = append(ds, normalizeDifference(d))
+	}
+	return ds
+}
+
+// normalizeDifference normalizes a numeric difference for json output.
+func normalizeDifference(d *Difference) *RecordData {
+	naWord := opts.Out.Miss
+	var base, cand, change, relative interface{} = naWord, naWord, naWord, naWord
+	if d.HasBaseline {
+		base = formatValue(d.Baseline, d.Metric)
+	}
+	if d.HasCandidate {
+		cand = formatValue(d.Candidate, d.Metric)
+	}
+	if d.Comparable() {
+		change = formatChange(d.Change(), d.Metric)
+		if r := d.RelativeChange(); !math.IsNaN(r) {
+			relative = roundTo(r, 2)
+		}
+	}
+	return diffRow(d.Name, d.Metric, base, cand, change, relative)
+}
+
+// diffRow returns a record that has only properties of a compared value.
+func diffRow(name, metric string, base, cand, change, relative interface{}) *RecordData {
+	return &RecordData{
+		Properties: []*JsonData{
+			{Name: "name", Value: name},
+			{Name: "metric", Value: metric},
+			{Name: "baseline", Value: base},
+			{Name: "candidate", Value: cand},
+			{Name: "change", Value: change},
+			{Name: "relativeChange", Value: relative},
+		},
+		Timings: make([]*TimingData, 0),
+	}
+}
+
+// formatChange formats signed change of dataType according to "-d, --duration" option.
+func formatChange(change float64, dataType string) interface{} {
+	if opts.Out.Duration == Human && isSeconds(dataType) {
+		sign := "+"
+		if change < 0 {
+			sign = "-"
+		}
+		return sign + formatSeconds(math.Abs(change))
+	}
+	return roundTo(change, 4)
+}
+
+// roundTo rounds value to the given number of decimal places.
+func roundTo(value float64, places int) float64 {
+	pow := math.Pow(10, float64(places))
+	return math.Round(value*pow) / pow
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"lsti/mes"
+)
+
+// followInterval is the interval to poll a file for appended content.
+const followInterval = time.Second
+
+// A followReader reads a growing file, and waits for content to be appended at the end of file.
+type followReader struct {
+	r io.Reader
+}
+
+// Read reads appended content, and never returns io.EOF.
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if n > 0 || err != io.EOF {
+			return n, err
+		}
+		time.Sleep(followInterval)
+	}
+}
+
+// A progress estimates completion of a running job from status lines.
+type progress struct {
+	// EndTime is the termination time of the simulation, or 0 if unknown.
+	EndTime float64
+
+	first, last *mes.Status
+}
+
+// Update updates progress with status, and reports whether the cycle advanced.
+// If status has no date, the current time is used as its date.
+func (p *progress) Update(status *mes.Status) bool {
+	if status.Date.IsZero() {
+		status.Date = time.Now()
+	}
+	if p.first == nil {
+		p.first = status
+	}
+	advanced := p.last == nil || status.Cycle != p.last.Cycle
+	p.last = status
+	return advanced
+}
+
+// Remaining returns estimated wall-clock time to reach the termination time.
+// It reports false if the termination time is unknown or the simulation time has not advanced.
+func (p *progress) Remaining() (time.Duration, bool) {
+	if p.EndTime <= 0 || p.last == nil {
+		return 0, false
+	}
+	elapsed := p.last.Date.Sub(p.first.Date).Seconds()
+	advanced := p.last.Time - p.first.Time
+	if elapsed <= 0 || advanced <= 0 {
+		return 0, false
+	}
+	remaining := (p.EndTime - p.last.Time) / (advanced / elapsed)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return time.Duration(remaining * float64(time.Second)), true
+}
+
+// String returns progress like "cycle 1000, time 1.0000E-03, dt 1.00E-06, 50.0%, remaining 0:00:40 (14:22:28)".
+func (p *progress) String() string {
+	status := p.last
+	str := fmt.Sprintf("cycle %d, time %.4E, dt %.2E", status.Cycle, status.Time, status.TimeStep)
+	if p.EndTime > 0 {
+		str += fmt.Sprintf(", %.1f%%", status.Time/p.EndTime*100)
+	}
+	if remaining, ok := p.Remaining(); ok {
+		completion := status.Date.Add(remaining)
+		str += fmt.Sprintf(", remaining %s (%s)", formatSeconds(remaining.Seconds()), completion.Format("15:04:05"))
+	}
+	return str
+}
+
+// Follow follows a message file of a running job and writes progress to stderr
+// for each status line, and writes timings of the file when the job is terminated.
+// Stdin is read until it is closed.
+func (cli *CLI) Follow(file string) error {
+	var r io.Reader
+	if file == stdinName {
+		r = cli.inStream
+	} else {
+		if archive, _, _ := splitArchivePath(file); isArchive(archive) {
+			return fmt.Errorf("Cannot follow archive: %s", file)
+		}
+		fp, err := openFile(file)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+		r = &followReader{r: fp}
+	}
+
+	// Content is kept to parse the whole file when terminated.
+	var content bytes.Buffer
+	p := &progress{EndTime: opts.Out.EndTime}
+	scanner := bufio.NewScanner(io.TeeReader(r, &content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if status, ok := mes.ParseStatus(line); ok {
+			if p.Update(status) {
+				fmt.Fprintln(cli.errStream, p)
+			}
+			continue
+		}
+		if t, ok := mes.ParseTerminationTime(line); ok && opts.Out.EndTime == 0 {
+			p.EndTime = t
+			continue
+		}
+		if mes.IsTermination(line) {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	src := &source{Name: file, Open: func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(&content), nil
+	}}
+	record, err := cli.ParseMessageFile(src)
+	if err != nil {
+		return err
+	}
+	for _, d := range record.Diagnostics {
+		fmt.Fprintln(cli.errStream, d)
+	}
+	if opts.Out.Strict && len(record.Diagnostics) > 0 {
+		return fmt.Errorf("%d problem(s) found while parsing files", len(record.Diagnostics))
+	}
+	if opts.Out.Long {
+		return cli.WriteLong(cli.NormalizeLong(record))
+	}
+	return cli.WriteData([]*RecordData{cli.NormalizeRecord(record)})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"path/filepath"
+
+	"lsti/mes"
+)
+
+// groupFields are record fields available for "-g, --group-by" option.
+var groupFields = map[string]func(*mes.Record) string{
+	"dir":       func(r *mes.Record) string { return filepath.Dir(r.File) },
+	"hostname":  func(r *mes.Record) string { return r.Hostname },
+	"inputFile": func(r *mes.Record) string { return r.InputFile },
+	"platform":  func(r *mes.Record) string { return r.Platform },
+	"version":   func(r *mes.Record) string { return r.Version },
+}
+
+// A recordGroup represents records that have the same key.
+type recordGroup struct {
+	Key     string
+	Records []*mes.Record
+}
+
+// groupRecords groups records by key in order of appearance.
+func groupRecords(records []*mes.Record, key func(*mes.Record) string) []*recordGroup {
+	var groups []*recordGroup
+	index := make(map[string]*recordGroup)
+	for _, record := range records {
+		k := key(record)
+		g, ok := index[k]
+		if !ok {
+			g = &recordGroup{Key: k}
+			index[k] = g
+			groups = append(groups, g)
+		}
+		g.Records = append(g.Records, record)
+	}
+	return groups
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+
+	"lsti/mes"
+)
+
+// WriteImbalance writes load imbalance among ranks of MPP records to stdout.
+func (cli *CLI) WriteImbalance(records []*mes.Record) error {
+	ds := cli.NormalizeImbalance(records)
+	if len(ds) == 0 {
+		return errors.New("No MPP timing information per processor found")
+	}
+	return cli.WriteData(ds)
+}
+
+// NormalizeImbalance normalizes load imbalance among ranks for json output.
+// Each record, metric (cpusec, clocksec) and category (the totals and each timing category)
+// becomes a record that has only properties.
+func (cli *CLI) NormalizeImbalance(records []*mes.Record) []*RecordData {
+	var ds []*RecordData
+	for _, record := range records {
+		for _, dataType := range []string{CpuSec, ClockSec} {
+			imbalances := []*mes.CategoryImbalance{{Name: "total", Imbalance: record.GetImbalance(dataType)}}
+			imbalances = append(imbalances, record.GetCategoryImbalances(dataType)...)
+			for _, imbalance := range imbalances {
+				// Skip columns not printed in the message file.
+				if imbalance.Imbalance == nil || imbalance.Max == 0 {
+					continue
+				}
+				ds = append(ds, &RecordData{
+					Properties: []*JsonData{
+						{Name: "file", Value: record.File},
+						{Name: "metric", Value: dataType},
+						{Name: "category", Value: imbalance.Name},
+						{Name: "ranks", Value: imbalance.NumRanks},
+						{Name: "min", Value: formatValue(imbalance.Min, dataType)},
+						{Name: "mean", Value: formatValue(imbalance.Mean, dataType)},
+						{Name: "max", Value: formatValue(imbalance.Max, dataType)},
+						{Name: "maxMean", Value: roundTo(imbalance.MaxMean, 4)},
+						{Name: "spread", Value: formatValue(imbalance.Spread, dataType)},
+						{Name: "slowestRank", Value: imbalance.Slowest},
+					},
+					Timings: make([]*TimingData, 0),
+				})
+			}
+		}
+	}
+	return ds
+}
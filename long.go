@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/parquet-go/parquet-go"
+
+	"lsti/mes"
+)
+
+// LongData is a row of long format, a value of a metric of a timing.
+// Segment is the 1-based index of segment with "--segments each", and Rank is the rank
+// of message file of a rank (e.g. mes0001) not merged with "--merge-ranks", otherwise nil.
+// Child is empty for parent timings.
+type LongData struct {
+	File    string  `json:"file" parquet:"file"`
+	Segment *int    `json:"segment" parquet:"segment,optional"`
+	Rank    *int64  `json:"rank" parquet:"rank,optional"`
+	Parent  string  `json:"parent" parquet:"parent"`
+	Child   string  `json:"child" parquet:"child"`
+	Metric  string  `json:"metric" parquet:"metric"`
+	Value   float64 `json:"value" parquet:"value"`
+}
+
+// longKeys are column names of long format.
+var longKeys = []string{"file", "segment", "rank", "parent", "child", "metric", "value"}
+
+// NormalizeLong normalizes timings of record for long format.
+// Each timing has a row for each of all metrics, values are in seconds or percent,
+// followed by derived metrics of "-t, --target" if available.
+func (cli *CLI) NormalizeLong(record *mes.Record) []*LongData {
+	var ls []*LongData
+	metrics := append([]string{}, allTargets...)
+	for _, t := range targets() {
+		if isDerived(t) {
+			metrics = append(metrics, t)
+		}
+	}
+	var segment *int
+	if record.Segment > 0 {
+		segment = &record.Segment
+	}
+	var rank *int64
+	if n, ok := mes.RankFileNumber(record.File); ok && record.RankFiles == nil {
+		rank = &n
+	}
+	add := func(parent, child string, data *mes.Data) {
+		for _, metric := range metrics {
+			if v, ok := record.GetMetric(data, metric); ok {
+				ls = append(ls, &LongData{File: record.File, Segment: segment, Rank: rank, Parent: parent, Child: child, Metric: metric, Value: v})
+			}
+		}
+	}
+	record.ForEachParent(func(parent *mes.Parent, _ int) {
+		add(parent.Name, "", &parent.Data)
+		if !opts.Out.Simple {
+			parent.ForEachChildren(func(child *mes.Child, _ int) {
+				add(parent.Name, child.Name, &child.Data)
+			})
+		}
+	})
+	return ls
+}
+
+// longData converts timings of normalized data to long format for long sheet of xlsx format,
+// whose values are in seconds (see formatValue). Segment and Rank are not set.
+// Records are identified by the first property (e.g. file), and metrics are "-t, --target".
+// Values that are not numbers (e.g. missing) are skipped.
+func longData(ds []*RecordData) []*LongData {
+	var ls []*LongData
+	ts := targets()
+	for _, d := range ds {
+		key := ""
+		if len(d.Properties) > 0 {
+			key = formatCell(d.Properties[0].Value)
+		}
+		add := func(parent, child string, value interface{}) {
+			m, ok := metricValues(value)
+			if !ok {
+				m = map[string]interface{}{ts[0]: value}
+			}
+			for _, t := range ts {
+				if v, ok := m[t].(float64); ok {
+					ls = append(ls, &LongData{File: key, Parent: parent, Child: child, Metric: t, Value: v})
+				}
+			}
+		}
+		for _, t := range d.Timings {
+			add(t.Name, "", t.Value)
+			for _, c := range t.Details {
+				add(t.Name, c.Name, c.Value)
+			}
+		}
+	}
+	return ls
+}
+
+// A longWriter writes rows of long format to stdout as they are given.
+type longWriter interface {
+	Write(ls []*LongData) error
+	Close() error
+}
+
+// newLongWriter returns writer of long format in "-o, --output" format
+// (csv, json, jsonl, parquet or tsv, default csv).
+func (cli *CLI) newLongWriter() (longWriter, error) {
+	if opts.Out.Query != "" {
+		return nil, errors.New("\"-q, --query\" option cannot be used with \"-l, --long\" option")
+	}
+	switch opts.Out.Output {
+	case "", Csv:
+		return newSeparatedLongWriter(cli.outStream, ','), nil
+	case Tsv:
+		return newSeparatedLongWriter(cli.outStream, '\t'), nil
+	case Json:
+		return &jsonLongWriter{w: cli.outStream}, nil
+	case Jsonl:
+		return &jsonLongWriter{w: cli.outStream, lines: true}, nil
+	case Parquet:
+		return &parquetLongWriter{w: parquet.NewGenericWriter[LongData](cli.outStream)}, nil
+	}
+	return nil, fmt.Errorf("Long format is not available in %s output", opts.Out.Output)
+}
+
+// WriteLong writes rows of long format to stdout.
+func (cli *CLI) WriteLong(ls []*LongData) error {
+	w, err := cli.newLongWriter()
+	if err != nil {
+		return err
+	}
+	if err := w.Write(ls); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// A separatedLongWriter writes long format as separated values with header.
+type separatedLongWriter struct {
+	w *csv.Writer
+}
+
+func newSeparatedLongWriter(w io.Writer, separator rune) *separatedLongWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+	writer.Write(longKeys)
+	return &separatedLongWriter{w: writer}
+}
+
+func (s *separatedLongWriter) Write(ls []*LongData) error {
+	for _, l := range ls {
+		segment, rank := "", ""
+		if l.Segment != nil {
+			segment = strconv.Itoa(*l.Segment)
+		}
+		if l.Rank != nil {
+			rank = strconv.FormatInt(*l.Rank, 10)
+		}
+		s.w.Write([]string{l.File, segment, rank, l.Parent, l.Child, l.Metric, strconv.FormatFloat(l.Value, 'g', -1, 64)})
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *separatedLongWriter) Close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// A jsonLongWriter writes long format as JSON array, or JSON Lines if lines is true.
+type jsonLongWriter struct {
+	w     io.Writer
+	lines bool
+	count int
+}
+
+func (j *jsonLongWriter) Write(ls []*LongData) error {
+	for _, l := range ls {
+		data, err := json.Marshal(l)
+		if err != nil {
+			return err
+		}
+		switch {
+		case j.lines:
+			fmt.Fprintf(j.w, "%s\n", data)
+		case j.count == 0:
+			fmt.Fprintf(j.w, "[\n  %s", data)
+		default:
+			fmt.Fprintf(j.w, ",\n  %s", data)
+		}
+		j.count++
+	}
+	return nil
+}
+
+func (j *jsonLongWriter) Close() error {
+	if j.lines {
+		return nil
+	}
+	if j.count == 0 {
+		fmt.Fprintln(j.w, "[]")
+	} else {
+		fmt.Fprint(j.w, "\n]\n")
+	}
+	return nil
+}
+
+// A parquetLongWriter writes long format as Parquet file.
+type parquetLongWriter struct {
+	w *parquet.GenericWriter[LongData]
+}
+
+func (p *parquetLongWriter) Write(ls []*LongData) error {
+	rows := make([]LongData, len(ls))
+	for i, l := range ls {
+		rows[i] = *l
+	}
+	_, err := p.w.Write(rows)
+	return err
+}
+
+func (p *parquetLongWriter) Close() error {
+	return p.w.Close()
+}
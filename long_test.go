@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+
+	"lsti/mes"
+)
+
+func TestWriteLongSegmentAndRank(t *testing.T) {
+	opts.Out.Target = ClockSec
+	opts.Out.Simple = true
+	defer func() { opts.Out.Simple = false }()
+	record, err := mes.ParseFile("mes/testdata/multiple_blocks_messag", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ls []*LongData
+	for i := range record.Segments {
+		ls = append(ls, (&CLI{}).NormalizeLong(record.GetSegment(i))...)
+	}
+	rank := &mes.Record{File: "run/mes0001"}
+	rank.AddParent("Element processing", 9, 90, 9, 90)
+	ls = append(ls, (&CLI{}).NormalizeLong(rank)...)
+
+	tests := []struct {
+		output string
+		want   []string
+	}{
+		{Csv, []string{
+			"file,segment,rank,parent,child,metric,value",
+			"mes/testdata/multiple_blocks_messag,1,,Keyword Processing,,clocksec,1",
+			"mes/testdata/multiple_blocks_messag,2,,Keyword Processing,,clocksec,2",
+			"run/mes0001,,1,Element processing,,clocksec,9",
+		}},
+		{Jsonl, []string{
+			`{"file":"mes/testdata/multiple_blocks_messag","segment":1,"rank":null,"parent":"Keyword Processing","child":"","metric":"clocksec","value":1}`,
+			`{"file":"mes/testdata/multiple_blocks_messag","segment":2,"rank":null,"parent":"Keyword Processing","child":"","metric":"clocksec","value":2}`,
+			`{"file":"run/mes0001","segment":null,"rank":1,"parent":"Element processing","child":"","metric":"clocksec","value":9}`,
+		}},
+	}
+	for _, tt := range tests {
+		opts.Out.Output = tt.output
+		out := new(bytes.Buffer)
+		cli := &CLI{outStream: out, errStream: new(bytes.Buffer)}
+		if err := cli.WriteLong(ls); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(out.String(), "\n")
+		for _, want := range tt.want {
+			found := false
+			for _, line := range lines {
+				found = found || line == want
+			}
+			if !found {
+				t.Errorf("%s output does not have %q:\n%s", tt.output, want, out)
+			}
+		}
+	}
+
+	// Parquet schema has optional segment and rank columns.
+	opts.Out.Output = Parquet
+	out := new(bytes.Buffer)
+	if err := (&CLI{outStream: out}).WriteLong(ls); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := parquet.Read[LongData](bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(ls) {
+		t.Fatalf("len(rows) = %d, want %d", len(rows), len(ls))
+	}
+	for i, row := range rows {
+		if (row.Segment == nil) != (ls[i].Segment == nil) || (row.Rank == nil) != (ls[i].Rank == nil) ||
+			row.Segment != nil && *row.Segment != *ls[i].Segment || row.Rank != nil && *row.Rank != *ls[i].Rank {
+			t.Errorf("rows[%d] = %+v, want segment and rank of %+v", i, row, ls[i])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"lsti/mes"
+)
+
+// WriteMemory writes memory of records to stdout.
+func (cli *CLI) WriteMemory(records []*mes.Record) error {
+	return cli.WriteData(cli.NormalizeMemory(records))
+}
+
+// NormalizeMemory normalizes memory of records for json output.
+// Each record becomes a record that has only properties, memories are in words
+// and the largest one is also in megabytes.
+func (cli *CLI) NormalizeMemory(records []*mes.Record) []*RecordData {
+	var ds []*RecordData
+	for _, record := range records {
+		memory := record.Memory
+		max := memory.GetMax()
+		ds = append(ds, &RecordData{
+			Properties: []*JsonData{
+				{Name: "file", Value: record.File},
+				{Name: "numCpus", Value: record.NumCpus},
+				{Name: "precision", Value: record.Precision},
+				{Name: "requested", Value: memory.Requested},
+				{Name: "requested2", Value: memory.Requested2},
+				{Name: "required", Value: memory.Required},
+				{Name: "required2", Value: memory.Required2},
+				{Name: "complete", Value: memory.Complete},
+				{Name: "complete2", Value: memory.Complete2},
+				{Name: "additional", Value: memory.Additional},
+				{Name: "total", Value: memory.Total},
+				{Name: "expansions", Value: memory.Expansions},
+				{Name: "expanded", Value: memory.Expanded},
+				{Name: "max", Value: max},
+				{Name: "maxMegabytes", Value: roundTo(float64(max*record.GetWordSize())/1e6, 1)},
+			},
+			Timings: make([]*TimingData, 0),
+		})
+	}
+	return ds
+}
@@ -0,0 +1,93 @@
+package mes
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// d3hspBanners are section banners printed only in d3hsp file.
+var d3hspBanners = []string{
+	"c o n t r o l   i n f o r m a t i o n",
+	"i n p u t   o f   n o d a l   d a t a",
+	"m a t e r i a l   p a r a m e t e r s",
+}
+
+// labelPattern matches d3hsp lines like "number of nodal points . . . . =   12345".
+var labelPattern = regexp.MustCompile(`^\s*([A-Za-z][^=]*?)[\s.]*=\s*(\S+)`)
+
+// isD3hspName reports whether file name (or archive member like runs.zip!d3hsp) is a d3hsp file.
+func isD3hspName(name string) bool {
+	base := filepath.Base(filepath.FromSlash(name))
+	if i := strings.LastIndex(base, "!"); i >= 0 {
+		base = base[i+1:]
+	}
+	return strings.HasPrefix(base, "d3hsp")
+}
+
+// parseD3hspLine parses model size, controls and mass scaling of a line of lineNumber.
+// It reports whether the line is a banner printed only in d3hsp file, and whether the line is parsed.
+// Labeled values are not d3hsp specific, since message file also prints some of them
+// (e.g. added mass of mass scaling). Invalid values are reported as diagnostics.
+func parseD3hspLine(record *Record, line string, lineNumber int) (d3hsp bool, ok bool) {
+	for _, banner := range d3hspBanners {
+		if strings.Contains(line, banner) {
+			return true, true
+		}
+	}
+
+	if !strings.Contains(line, "=") {
+		return false, false
+	}
+	results := labelPattern.FindStringSubmatch(line)
+	if results == nil {
+		return false, false
+	}
+	label := strings.ToLower(strings.Join(strings.Fields(results[1]), " "))
+	value := results[2]
+
+	// parseCount parses value to n and reports that the line is parsed.
+	parseCount := func(n *int64) (bool, bool) {
+		var err error
+		if *n, err = strconv.ParseInt(value, 10, 64); err != nil {
+			record.AddDiagnostic(Warning, lineNumber, "invalid %s: %q", label, value)
+		}
+		return false, true
+	}
+	parseValue := func(v *float64) (bool, bool) {
+		var err error
+		if *v, err = strconv.ParseFloat(value, 64); err != nil {
+			record.AddDiagnostic(Warning, lineNumber, "invalid %s: %q", label, value)
+		}
+		return false, true
+	}
+
+	switch {
+	case label == "number of nodal points":
+		return parseCount(&record.Model.NumNodes)
+	case label == "number of solid elements":
+		return parseCount(&record.Model.NumSolids)
+	case label == "number of shell elements":
+		return parseCount(&record.Model.NumShells)
+	case strings.HasPrefix(label, "number of") && strings.HasSuffix(label, "thick shell elements"):
+		return parseCount(&record.Model.NumThickShells)
+	case label == "number of beam elements":
+		return parseCount(&record.Model.NumBeams)
+	case label == "number of parts":
+		return parseCount(&record.Model.NumParts)
+	case label == "termination time":
+		return parseValue(&record.Control.TerminationTime)
+	case label == "scale factor for computed time step", label == "time step scale factor":
+		return parseValue(&record.Control.TimeStepScale)
+	case label == "time step size for mass scaled solution":
+		return parseValue(&record.Control.MassScaledTimeStep)
+	case label == "added mass":
+		return parseValue(&record.MassScaling.AddedMass)
+	case label == "physical mass":
+		return parseValue(&record.MassScaling.PhysicalMass)
+	case label == "ratio" && record.MassScaling.PhysicalMass != 0:
+		return parseValue(&record.MassScaling.Ratio)
+	}
+	return false, false
+}
@@ -0,0 +1,35 @@
+package mes
+
+import "testing"
+
+func TestParseD3hsp(t *testing.T) {
+	record := parseTestFile(t, "r12_smp_d3hsp")
+	if record.FileType != D3hspFile {
+		t.Errorf("FileType = %q, want %q", record.FileType, D3hspFile)
+	}
+	wantModel := Model{NumNodes: 12345, NumParts: 3, NumSolids: 8000, NumShells: 2000, NumThickShells: 50, NumBeams: 10}
+	if record.Model != wantModel {
+		t.Errorf("Model = %+v, want %+v", record.Model, wantModel)
+	}
+	wantControl := Control{TerminationTime: 2e-3, TimeStepScale: 0.9, MassScaledTimeStep: 1e-6}
+	if record.Control != wantControl {
+		t.Errorf("Control = %+v, want %+v", record.Control, wantControl)
+	}
+	if record.MassScaling.Ratio != 1e-2 {
+		t.Errorf("MassScaling.Ratio = %v, want 0.01", record.MassScaling.Ratio)
+	}
+	if record.ElapsedTime != 132 || len(record.Parents) != 4 {
+		t.Errorf("ElapsedTime = %v, len(Parents) = %d, want 132 and 4 timings", record.ElapsedTime, len(record.Parents))
+	}
+}
+
+func TestParseMassScalingMessage(t *testing.T) {
+	// Message file also prints added mass of mass scaling, which is not a d3hsp banner.
+	record := parseTestFile(t, "mass_scaling_messag")
+	if record.FileType != MessageFile {
+		t.Errorf("FileType = %q, want %q", record.FileType, MessageFile)
+	}
+	if record.MassScaling.Ratio != 1e-2 {
+		t.Errorf("MassScaling.Ratio = %v, want 0.01", record.MassScaling.Ratio)
+	}
+}
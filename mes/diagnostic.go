@@ -0,0 +1,43 @@
+package mes
+
+import "fmt"
+
+// Severities of Diagnostic.
+const (
+	Warning = "warning"
+	Error   = "error"
+)
+
+// A Diagnostic represents a problem found while parsing a file.
+type Diagnostic struct {
+	File string
+
+	// Line is the line number starting at 1, or 0 if the problem is not about a line.
+	Line int
+
+	// Severity is Warning or Error.
+	Severity string
+
+	Message string
+}
+
+// String returns diagnostic as "file:line: severity: message".
+func (d *Diagnostic) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, d.Severity, d.Message)
+}
+
+// AddDiagnostic adds a diagnostic about line to record.
+func (record *Record) AddDiagnostic(severity string, line int, format string, args ...interface{}) *Diagnostic {
+	d := Diagnostic{
+		File:     record.File,
+		Line:     line,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	record.Diagnostics = append(record.Diagnostics, &d)
+	return &d
+}
@@ -0,0 +1,104 @@
+package mes
+
+// An Imbalance represents load imbalance of a value among ranks.
+type Imbalance struct {
+	NumRanks       int
+	Min, Max, Mean float64
+
+	// MaxMean is the ratio of maximum to mean (1 means perfectly balanced).
+	MaxMean float64
+
+	// Spread is the difference between maximum and minimum.
+	Spread float64
+
+	// Slowest is the rank that has maximum value.
+	Slowest int64
+}
+
+// NewImbalance computes load imbalance of values, values[i] belongs to ranks[i].
+// It returns nil if no values are given.
+func NewImbalance(ranks []int64, values []float64) *Imbalance {
+	if len(values) == 0 {
+		return nil
+	}
+	imbalance := Imbalance{
+		NumRanks: len(values),
+		Min:      values[0],
+		Max:      values[0],
+		Slowest:  ranks[0],
+	}
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if v < imbalance.Min {
+			imbalance.Min = v
+		}
+		if v > imbalance.Max {
+			imbalance.Max = v
+			imbalance.Slowest = ranks[i]
+		}
+	}
+	imbalance.Mean = sum / float64(len(values))
+	if imbalance.Mean != 0 {
+		imbalance.MaxMean = imbalance.Max / imbalance.Mean
+	}
+	imbalance.Spread = imbalance.Max - imbalance.Min
+	return &imbalance
+}
+
+// A CategoryImbalance represents load imbalance of a timing category among ranks.
+type CategoryImbalance struct {
+	// Name is the name of timing category (e.g. Element processing).
+	Name string
+	*Imbalance
+}
+
+// GetCategoryImbalances returns load imbalance among ranks of each timing category
+// for dataType (CpuSec or ClockSec) in order of timings. Values of ranks are taken from
+// the table per processor of the category, or from timings of rank files if merged
+// (see MergeRankFiles). Categories without values of ranks are omitted.
+func (record *Record) GetCategoryImbalances(dataType string) []*CategoryImbalance {
+	var names []string
+	found := make(map[string]bool)
+	addName := func(name string) {
+		if !found[name] {
+			found[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, parent := range record.Parents {
+		addName(parent.Name)
+	}
+	for _, rank := range record.Ranks {
+		if rank.Category != "" {
+			addName(rank.Category)
+		}
+	}
+
+	var imbalances []*CategoryImbalance
+	for _, name := range names {
+		var ids []int64
+		var values []float64
+		for _, rank := range record.Ranks {
+			if rank.Category == name {
+				ids = append(ids, rank.Rank)
+				values = append(values, rank.GetValue(dataType))
+			}
+		}
+		if files := record.RankFiles; len(values) == 0 && files != nil {
+			for i, parents := range files.Parents {
+				for _, parent := range parents {
+					if parent.Name == name {
+						ids = append(ids, files.Ranks[i])
+						values = append(values, parent.GetValue(dataType))
+						break
+					}
+				}
+			}
+		}
+		if imbalance := NewImbalance(ids, values); imbalance != nil {
+			imbalances = append(imbalances, &CategoryImbalance{Name: name, Imbalance: imbalance})
+		}
+	}
+	return imbalances
+}
@@ -0,0 +1,68 @@
+package mes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewImbalance(t *testing.T) {
+	imbalance := NewImbalance([]int64{0, 1, 2}, []float64{90, 120, 90})
+	if imbalance.Max != 120 || imbalance.Mean != 100 || imbalance.MaxMean != 1.2 ||
+		imbalance.Spread != 30 || imbalance.Slowest != 1 {
+		t.Errorf("NewImbalance() = %+v, want max 120, mean 100, spread 30 and slowest rank 1", imbalance)
+	}
+	if imbalance := NewImbalance(nil, nil); imbalance != nil {
+		t.Errorf("NewImbalance() of no values = %+v, want nil", imbalance)
+	}
+}
+
+func TestGetImbalance(t *testing.T) {
+	record := parseTestFile(t, "r9_mpp_mes0000")
+	if len(record.Ranks) != 4 {
+		t.Fatalf("len(Ranks) = %d, want 4", len(record.Ranks))
+	}
+
+	total := record.GetImbalance(CpuSec)
+	if total == nil || total.NumRanks != 2 || total.Max != 110 || total.Slowest != 0 {
+		t.Errorf("GetImbalance(CpuSec) = %+v, want 2 ranks with max 110 of rank 0", total)
+	}
+
+	imbalances := record.GetCategoryImbalances(CpuSec)
+	if len(imbalances) != 1 {
+		t.Fatalf("GetCategoryImbalances(CpuSec) = %d categories, want 1", len(imbalances))
+	}
+	if got := imbalances[0]; got.Name != "Element processing" || got.Max != 108 || got.MaxMean != 1.2 {
+		t.Errorf("GetCategoryImbalances(CpuSec)[0] = %s %+v, want Element processing with max 108", got.Name, got.Imbalance)
+	}
+}
+
+func TestGetCategoryImbalancesOfRankFiles(t *testing.T) {
+	rank := func(file string, elementProcessing float64) *Record {
+		record := &Record{File: file, NumCpus: 2}
+		record.AddParent("Keyword Processing", 1, 0, 1, 0)
+		record.AddParent("Element processing", elementProcessing, 0, elementProcessing, 0)
+		return record
+	}
+	merged := MergeRankFiles([]*Record{rank("run/mes0000", 60), rank("run/mes0001", 100)})
+
+	imbalances := merged.GetCategoryImbalances(ClockSec)
+	if len(imbalances) != 2 {
+		t.Fatalf("GetCategoryImbalances(ClockSec) = %d categories, want 2", len(imbalances))
+	}
+	if got := imbalances[1]; got.Name != "Element processing" || got.Mean != 80 || got.Slowest != 1 {
+		t.Errorf("GetCategoryImbalances(ClockSec)[1] = %s %+v, want Element processing with mean 80 and slowest rank 1", got.Name, got.Imbalance)
+	}
+	if total := merged.GetImbalance(ClockSec); total == nil || total.Max != 101 {
+		t.Errorf("GetImbalance(ClockSec) = %+v, want max 101 of rank files", total)
+	}
+}
+
+func TestParseProcessorHostnameInOneField(t *testing.T) {
+	record, err := Parse(strings.NewReader(" Processor/Hostname\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Ranks) != 0 {
+		t.Errorf("len(Ranks) = %d, want 0", len(record.Ranks))
+	}
+}
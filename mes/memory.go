@@ -0,0 +1,134 @@
+package mes
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Memory sizes are printed in words with optional suffix K, M or G (e.g. 12M).
+var (
+	// memoryRequestedPattern matches lines like "Memory size from command line:      200000000,     100000000".
+	memoryRequestedPattern = regexp.MustCompile(`Memory size from (?:command line|keyword)\s*:\s*([\d.]+[KMG]?)(?:\s*,\s*([\d.]+[KMG]?))?`)
+
+	// memoryRequiredPattern matches lines like "Memory required to begin solution (memory=  12M memory2=  3M)".
+	memoryRequiredPattern = regexp.MustCompile(`Memory required to (begin|complete) solution\s*(?:\(memory=|:)\s*([\d.]+[KMG]?)(?:\s+memory2=\s*([\d.]+[KMG]?))?`)
+
+	// memoryAdditionalPattern matches lines like "Additional dynamically allocated memory:   1234K".
+	memoryAdditionalPattern = regexp.MustCompile(`Additional dynamically allocated memory\s*:\s*([\d.]+[KMG]?)`)
+
+	// memoryTotalPattern matches the total line following additional dynamically allocated memory.
+	memoryTotalPattern = regexp.MustCompile(`^\s*Total\s*:\s*([\d.]+[KMG]?)`)
+
+	// memoryExpandedPattern matches lines of dynamic memory expansion like "Memory is expanded to 250000000 words".
+	memoryExpandedPattern = regexp.MustCompile(`(?i)(?:expanding memory to|memory (?:is )?expanded to)\s*([\d.]+[KMG]?)`)
+)
+
+// A Memory represents the memory (in words) requested and required for the solution.
+type Memory struct {
+	// Requested is "memory", and Requested2 is "memory2" for MPP, given by command line or keyword.
+	Requested, Requested2 int64
+
+	// Required is "memory", and Required2 is "memory2" for MPP, required to begin solution.
+	Required, Required2 int64
+
+	// Complete is "memory", and Complete2 is "memory2" for MPP, required to complete solution.
+	Complete, Complete2 int64
+
+	// Additional is additional dynamically allocated memory, and Total is the total with it.
+	Additional, Total int64
+
+	// Expansions is the number of dynamic memory expansions, and Expanded is the last size expanded to.
+	Expansions int
+	Expanded   int64
+}
+
+// parseMemoryLine parses memory of a line of lineNumber, and reports whether the line is parsed.
+// Invalid sizes are reported as diagnostics.
+func parseMemoryLine(record *Record, line string, lineNumber int) bool {
+	memory := &record.Memory
+	// words parses size of name, empty if not printed (e.g. memory2 of SMP).
+	words := func(name, str string) int64 {
+		if str == "" {
+			return 0
+		}
+		value, err := parseWords(str)
+		if err != nil {
+			record.AddDiagnostic(Warning, lineNumber, "invalid %s: %q", name, str)
+		}
+		return value
+	}
+	if strings.Contains(line, "emory") {
+		if results := memoryRequestedPattern.FindStringSubmatch(line); results != nil {
+			memory.Requested = words("requested memory", results[1])
+			memory.Requested2 = words("requested memory2", results[2])
+			return true
+		}
+		if results := memoryRequiredPattern.FindStringSubmatch(line); results != nil {
+			if results[1] == "begin" {
+				memory.Required = words("required memory", results[2])
+				memory.Required2 = words("required memory2", results[3])
+			} else {
+				memory.Complete = words("memory to complete", results[2])
+				memory.Complete2 = words("memory2 to complete", results[3])
+			}
+			return true
+		}
+		if results := memoryAdditionalPattern.FindStringSubmatch(line); results != nil {
+			memory.Additional = words("additional memory", results[1])
+			return true
+		}
+		if results := memoryExpandedPattern.FindStringSubmatch(line); results != nil {
+			memory.Expansions++
+			memory.Expanded = words("expanded memory", results[1])
+			return true
+		}
+	}
+	if memory.Additional != 0 && memory.Total == 0 {
+		if results := memoryTotalPattern.FindStringSubmatch(line); results != nil {
+			memory.Total = words("total memory", results[1])
+			return true
+		}
+	}
+	return false
+}
+
+// parseWords parses number of words with optional suffix K, M or G (e.g. 12M).
+func parseWords(str string) (int64, error) {
+	scale := 1.0
+	switch {
+	case strings.HasSuffix(str, "K"):
+		scale = 1e3
+	case strings.HasSuffix(str, "M"):
+		scale = 1e6
+	case strings.HasSuffix(str, "G"):
+		scale = 1e9
+	}
+	value, err := strconv.ParseFloat(strings.TrimRight(str, "KMG"), 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(value * scale), nil
+}
+
+// GetMax returns the largest memory of all memories in words.
+func (memory *Memory) GetMax() int64 {
+	max := int64(0)
+	for _, words := range []int64{
+		memory.Requested, memory.Requested2, memory.Required, memory.Required2,
+		memory.Complete, memory.Complete2, memory.Additional, memory.Total, memory.Expanded,
+	} {
+		if words > max {
+			max = words
+		}
+	}
+	return max
+}
+
+// GetWordSize returns the size of a word in bytes, 8 for double precision and 4 otherwise.
+func (record *Record) GetWordSize() int64 {
+	if strings.Contains(strings.ToLower(record.Precision), "double") || strings.Contains(record.Precision, "R8") {
+		return 8
+	}
+	return 4
+}
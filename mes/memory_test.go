@@ -0,0 +1,53 @@
+package mes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMemory(t *testing.T) {
+	content := strings.Join([]string{
+		" Memory size from command line:      200000000,     100000000",
+		" Memory required to begin solution (memory=  12M memory2=  3M)",
+		" Additional dynamically allocated memory:   1234K",
+		"                                   Total:   13.2M",
+		" Memory is expanded to 250000000 words",
+	}, "\n")
+	record, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Memory{
+		Requested: 200000000, Requested2: 100000000,
+		Required: 12000000, Required2: 3000000,
+		Additional: 1234000, Total: 13200000,
+		Expansions: 1, Expanded: 250000000,
+	}
+	if record.Memory != want {
+		t.Errorf("Memory = %+v, want %+v", record.Memory, want)
+	}
+}
+
+func TestParseInvalidValues(t *testing.T) {
+	content := strings.Join([]string{
+		" Memory required to begin solution (memory=  1.2.3M memory2=  3M)",
+		" number of nodal points . . . . . . . . . . =  *******",
+		" termination time . . . . . . . . . . . . . =  1.0E-0x",
+	}, "\n")
+	record, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, d := range record.Diagnostics {
+		if d.Line > 0 {
+			lines = append(lines, d.Line)
+		}
+	}
+	if len(lines) != 3 || lines[0] != 1 || lines[1] != 2 || lines[2] != 3 {
+		t.Errorf("Diagnostics = %v, want invalid values of lines 1, 2 and 3", record.Diagnostics)
+	}
+	if record.Memory.Required != 0 || record.Memory.Required2 != 3000000 {
+		t.Errorf("Memory = %+v, want required 0 and required2 3000000", record.Memory)
+	}
+}
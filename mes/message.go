@@ -0,0 +1,88 @@
+package mes
+
+import (
+	"regexp"
+	"strings"
+)
+
+// messagePattern matches warning and error messages like "*** Warning 10130 (SOL+130)".
+var messagePattern = regexp.MustCompile(`^\s*\*\*\*\s*(Warning|Error)\b[\s:]*(\d+)?\s*(?:\(([A-Z]+\+\d+)\))?\s*(.*)$`)
+
+// A Message represents warning or error messages printed with the same code.
+type Message struct {
+	// Severity is Warning or Error.
+	Severity string
+
+	// Code is the message number (e.g. "10130"), or the message id (e.g. "SOL+130")
+	// if not numbered, or empty if neither printed.
+	Code string
+
+	// Count is the number of times printed.
+	Count int
+
+	// Line is the line number of the first occurrence, and Text is its text.
+	Line int
+	Text string
+}
+
+// A messageParser collects messages of a record line by line.
+type messageParser struct {
+	messages []*Message
+	index    map[string]*Message
+
+	// pending is the message whose text is printed in the next line.
+	pending *Message
+}
+
+// parseLine parses line of lineNumber, and reports whether line is a message.
+func (p *messageParser) parseLine(line string, lineNumber int) bool {
+	if p.pending != nil {
+		if text := strings.TrimSpace(line); text != "" {
+			p.pending.Text = text
+			p.pending = nil
+		}
+	}
+	if !strings.Contains(line, "***") {
+		return false
+	}
+	results := messagePattern.FindStringSubmatch(line)
+	if results == nil {
+		return false
+	}
+	severity := Warning
+	if results[1] == "Error" {
+		severity = Error
+	}
+	code := results[2]
+	if code == "" {
+		code = results[3]
+	}
+	key := severity + " " + code
+	if message, ok := p.index[key]; ok {
+		message.Count++
+		return true
+	}
+
+	message := &Message{Severity: severity, Code: code, Count: 1, Line: lineNumber}
+	message.Text = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(results[4]), "*"))
+	if message.Text == "" {
+		p.pending = message
+	}
+	if p.index == nil {
+		p.index = make(map[string]*Message)
+	}
+	p.index[key] = message
+	p.messages = append(p.messages, message)
+	return true
+}
+
+// GetNumMessages returns the number of messages of severity (Warning or Error) printed in this record.
+func (record *Record) GetNumMessages(severity string) int {
+	n := 0
+	for _, message := range record.Messages {
+		if message.Severity == severity {
+			n += message.Count
+		}
+	}
+	return n
+}
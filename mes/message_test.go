@@ -0,0 +1,33 @@
+package mes
+
+import "testing"
+
+func TestParseMessages(t *testing.T) {
+	record := parseTestFile(t, "messages_messag")
+	want := []Message{
+		// Text printed in the next line of the first occurrence.
+		{Severity: Warning, Code: "10130", Count: 2, Line: 34, Text: "part 3 has no elements"},
+		{Severity: Warning, Code: "40509", Count: 2, Line: 36, Text: "contact dt is smaller than solid dt"},
+		// Message id is the code if not numbered.
+		{Severity: Warning, Code: "MEM+12", Count: 1, Line: 41, Text: "memory is expanded"},
+		{Severity: Error, Code: "40131", Count: 1, Line: 43, Text: "negative volume in solid element 1234"},
+	}
+	if len(record.Messages) != len(want) {
+		t.Fatalf("Messages = %d, want %d", len(record.Messages), len(want))
+	}
+	for i, w := range want {
+		if *record.Messages[i] != w {
+			t.Errorf("Messages[%d] = %+v, want %+v", i, *record.Messages[i], w)
+		}
+	}
+	if n := record.GetNumMessages(Warning); n != 5 {
+		t.Errorf("GetNumMessages(Warning) = %d, want 5", n)
+	}
+	if n := record.GetNumMessages(Error); n != 1 {
+		t.Errorf("GetNumMessages(Error) = %d, want 1", n)
+	}
+	// Progress lines between messages are still parsed.
+	if len(record.Progress) != 2 {
+		t.Errorf("len(Progress) = %d, want 2", len(record.Progress))
+	}
+}
@@ -0,0 +1,55 @@
+package mes
+
+import "strings"
+
+// Derived metric names select a value computed from Data and its Record.
+const (
+	// CpuRatio is the ratio of CPU time to wall-clock time.
+	CpuRatio = "cpuratio"
+
+	// CoreUtilization is CPU time divided by wall-clock time and number of CPUs in percent.
+	// It is available only for SMP, since CPU time of MPP is that of a rank.
+	CoreUtilization = "putil"
+
+	// ElementCycle is wall-clock time in microseconds per element per cycle.
+	ElementCycle = "uselemcycle"
+)
+
+// GetNumElements returns the number of elements (solids, shells, thick shells and beams)
+// in this record, which is printed in d3hsp file.
+func (record *Record) GetNumElements() int64 {
+	m := record.Model
+	return m.NumSolids + m.NumShells + m.NumThickShells + m.NumBeams
+}
+
+// IsMpp reports whether this record is of MPP execution.
+func (record *Record) IsMpp() bool {
+	return strings.Contains(record.Version, "mpp") || len(record.Ranks) > 0 || record.RankFiles != nil
+}
+
+// GetMetric returns value of dataType of data in this record.
+// dataType is one of the metric names (e.g. CpuSec) or the derived metric names (e.g. CpuRatio).
+// It reports false if a derived metric is not available (e.g. no wall-clock time,
+// model size and cycles not printed, or core utilization of MPP).
+func (record *Record) GetMetric(data *Data, dataType string) (float64, bool) {
+	switch dataType {
+	case CpuRatio:
+		if data.ClockSec == 0 {
+			return 0, false
+		}
+		return data.CpuSec / data.ClockSec, true
+	case CoreUtilization:
+		if data.ClockSec == 0 || record.NumCpus == 0 || record.IsMpp() {
+			return 0, false
+		}
+		return data.CpuSec / data.ClockSec / float64(record.NumCpus) * 100, true
+	case ElementCycle:
+		elements := record.GetNumElements()
+		cycles := record.Termination.Cycle
+		if elements == 0 || cycles == 0 {
+			return 0, false
+		}
+		return data.ClockSec * 1e6 / float64(elements) / float64(cycles), true
+	}
+	return data.GetValue(dataType), true
+}
@@ -0,0 +1,27 @@
+package mes
+
+import "testing"
+
+func TestGetMetric(t *testing.T) {
+	data := &Data{Name: "Element processing", CpuSec: 40, ClockSec: 10}
+	tests := []struct {
+		name     string
+		record   *Record
+		dataType string
+		want     float64
+		ok       bool
+	}{
+		{"cpuratio", &Record{NumCpus: 4}, CpuRatio, 4, true},
+		{"putil of SMP", &Record{Version: "smp d R12.0.0", NumCpus: 8}, CoreUtilization, 50, true},
+		{"putil of MPP", &Record{Version: "mpp d R12.0.0", NumCpus: 8}, CoreUtilization, 0, false},
+		{"putil of merged ranks", &Record{NumCpus: 8, RankFiles: &RankFiles{}}, CoreUtilization, 0, false},
+		{"putil without CPUs", &Record{}, CoreUtilization, 0, false},
+		{"uselemcycle without d3hsp", &Record{NumCpus: 4}, ElementCycle, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.record.GetMetric(data, tt.dataType)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: GetMetric() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
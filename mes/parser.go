@@ -0,0 +1,398 @@
+// Package mes parses LS-DYNA message files (e.g. messag, mes****) and
+// extracts header and timing information from them.
+package mes
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Options controls how ParseFile stores the file path in Record.File.
+type Options struct {
+	// Absolute stores the absolute path of the file.
+	Absolute bool
+
+	// Relative stores the file path relative to this directory.
+	// If Absolute is true, Relative is ignored.
+	Relative string
+}
+
+// ParseFile parses LS-DYNA message file (e.g. messag, mes****) or d3hsp file and return record.
+// A nil options stores the file path as given.
+func ParseFile(name string, options *Options) (*Record, error) {
+	fp, err := os.Open(filepath.FromSlash(name))
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	return ParseReader(fp, name, options)
+}
+
+// ParseReader parses content of file name read from r (e.g. decompressed file or archive member)
+// and return record. Record.File, Record.Path and Record.Hash are set as ParseFile does.
+func ParseReader(r io.Reader, name string, options *Options) (*Record, error) {
+	hash := sha256.New()
+	record, err := Parse(io.TeeReader(r, hash))
+	if err != nil {
+		return nil, err
+	}
+	// Read the rest not scanned, so that the hash covers the whole content.
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, err
+	}
+	record.Hash = hex.EncodeToString(hash.Sum(nil))
+	record.File = TranslatePath(name, options)
+	record.Path = AbsPath(name)
+	for _, d := range record.Diagnostics {
+		d.File = record.File
+	}
+	if isD3hspName(name) {
+		record.FileType = D3hspFile
+	}
+	return record, nil
+}
+
+// AbsPath returns absolute path of file name as Record.Path, or name if it cannot be resolved.
+func AbsPath(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return name
+}
+
+// TranslatePath translates file path according to options.
+func TranslatePath(name string, options *Options) string {
+	if options == nil {
+		return name
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return name
+	}
+	if options.Absolute {
+		return abs
+	}
+	if options.Relative != "" {
+		rel, err := filepath.Rel(filepath.FromSlash(options.Relative), abs)
+		if err == nil {
+			return rel
+		}
+	}
+	return name
+}
+
+// Parse reads LS-DYNA message file or d3hsp file content from r and return record.
+// Record.FileType is detected from the content, and Record.File is left empty.
+func Parse(r io.Reader) (*Record, error) {
+	record := Record{}
+	scanner := bufio.NewScanner(r)
+	start := false
+	end := false
+	var layout *timingLayout
+	const (
+		SMP = "smp"
+		MPP = "mpp"
+	)
+	var currentParent *Parent
+	var segment *Segment
+	var moduleType string
+	var rankColumns []string
+	var rankCategory string
+	var title string
+	lineNumber := 0
+	var termination terminationParser
+	var messages messageParser
+
+	// parseIntField parses integer field of the current line, and adds a warning if invalid.
+	parseIntField := func(name string, line string, start, end int) int64 {
+		value, err := parseInt([]rune(line), start, end)
+		if err != nil {
+			record.AddDiagnostic(Warning, lineNumber, "invalid %s: %q", name, parseText([]rune(line), start, end))
+		}
+		return value
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber++
+		// The last line that is not blank nor separator may be the title of the next table.
+		previous := title
+		if strings.TrimSpace(line) != "" && !isSeparator(line) {
+			title = line
+		}
+		termination.parseLine(line, lineNumber)
+		if messages.parseLine(line, lineNumber) {
+			continue
+		}
+
+		// Search for MPP timing information per processor.
+		if fields := strings.Fields(line); len(fields) >= 2 && strings.Contains(line, "Processor") && strings.Contains(line, "Hostname") {
+			// Columns after "Processor" and "Hostname" (e.g. CPU/Avg_CPU, CPU(seconds)).
+			rankColumns = fields[2:]
+			rankCategory = parseRankCategory(previous, record.Segments)
+			continue
+		}
+		if rankColumns != nil {
+			if rank, ok := parseRank(line, rankColumns); ok {
+				rank.Category = rankCategory
+				record.Ranks = append(record.Ranks, rank)
+				continue
+			}
+			if !strings.Contains(line, "-----") {
+				rankColumns = nil
+			}
+		}
+
+		// Search for memory.
+		if parseMemoryLine(&record, line, lineNumber) {
+			continue
+		}
+
+		// Search for d3hsp information (e.g. model size).
+		if d3hsp, ok := parseD3hspLine(&record, line, lineNumber); ok {
+			if d3hsp {
+				record.FileType = D3hspFile
+			}
+			continue
+		}
+
+		// Search for status lines of the solution.
+		if status, ok := ParseStatus(line); ok {
+			record.Progress = append(record.Progress, status)
+			continue
+		}
+
+		// Search for header information.
+		if !start {
+			if strings.Contains(line, "Version : ") {
+				record.Version = parseText([]rune(line), 18, 34)
+				record.Date = parseText([]rune(line), 34, 55)
+				if strings.Contains(record.Version, "smp") {
+					moduleType = SMP
+				} else if strings.Contains(record.Version, "mpp") {
+					moduleType = MPP
+				}
+				continue
+			}
+			if strings.Contains(line, "Revision: ") {
+				record.Revision = parseIntField("revision", line, 18, 34)
+				record.Time = parseText([]rune(line), 34, 55)
+				continue
+			}
+			if strings.Contains(line, "Licensed to: ") {
+				record.LicensedTo = parseText([]rune(line), 21, 55)
+				continue
+			}
+			if strings.Contains(line, "Issued by  : ") {
+				record.IssuedBy = parseText([]rune(line), 21, 55)
+				continue
+			}
+			if strings.Contains(line, "Platform   : ") {
+				record.Platform = parseText([]rune(line), 21, 55)
+				continue
+			}
+			if strings.Contains(line, "OS Level   : ") {
+				record.Os = parseText([]rune(line), 21, 55)
+				continue
+			}
+			if strings.Contains(line, "Compiler   : ") {
+				record.Compiler = parseText([]rune(line), 21, 55)
+				continue
+			}
+			if strings.Contains(line, "Hostname   : ") {
+				record.Hostname = parseText([]rune(line), 21, 55)
+				continue
+			}
+			if strings.Contains(line, "Precision  : ") {
+				record.Precision = parseText([]rune(line), 21, 55)
+				continue
+			}
+			if strings.Contains(line, "SVN Version: ") {
+				record.SvnVersion = parseIntField("SVN version", line, 21, 55)
+				continue
+			}
+			if strings.Contains(line, "Input file: ") {
+				record.InputFile = parseText([]rune(line), 13, 84)
+				continue
+			}
+			if moduleType == MPP && strings.HasPrefix(line, " MPP execution with") {
+				record.NumCpus = parseIntField("number of CPUs", line, 19, 27)
+				continue
+			}
+		}
+
+		// Search for timing information block.
+		// Each block becomes a segment (e.g. restarted or multi-stage run).
+		if strings.HasPrefix(line, " T i m i n g   i n f o r m a t i o n") {
+			start = true
+			end = false
+			layout = nil
+			currentParent = nil
+			segment = &Segment{}
+			record.Segments = append(record.Segments, segment)
+			continue
+		}
+		if !start {
+			continue
+		}
+
+		// Parse timing information.
+		if !end {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			// Detect column layout from the header line.
+			if layout == nil {
+				var isHeader bool
+				layout, isHeader = detectTimingLayout(line)
+				if isHeader {
+					continue
+				}
+			}
+
+			// Separator lines enclose timing rows.
+			if isSeparator(line) {
+				if len(segment.Parents) > 0 {
+					end = true
+				}
+				continue
+			}
+
+			row, err := layout.parseRow(line)
+			if err != nil {
+				record.AddDiagnostic(Warning, lineNumber, "invalid timing row: %v", err)
+				continue
+			}
+			if row.IsParent || currentParent == nil {
+				currentParent = segment.AddParent(row.Name, row.CpuSec, row.CpuPercent, row.ClockSec, row.ClockPercent)
+			} else {
+				currentParent.AddChild(row.Name, row.CpuSec, row.CpuPercent, row.ClockSec, row.ClockPercent)
+			}
+			continue
+		}
+
+		// Search for footer information.
+		if moduleType == SMP && strings.HasPrefix(line, " Number of CPU's") {
+			record.NumCpus = parseIntField("number of CPUs", line, 16, 21)
+			continue
+		}
+		if strings.HasPrefix(line, " N o r m a l    t e r m i n a t i o n") {
+			record.NormalTermination = true
+			continue
+		}
+		if strings.HasPrefix(line, " Elapsed time") {
+			// Use regexp because Elapsed time is not a fixed format.
+			r := regexp.MustCompile(`^ Elapsed time\s*(\d+)\s*seconds`)
+			results := r.FindStringSubmatch(line)
+			if len(results) == 2 {
+				seconds, _ := strconv.ParseFloat(results[1], 64)
+				segment.ElapsedTime = seconds
+				record.ElapsedTime += seconds
+			}
+			continue
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(record.Segments) > 0 {
+		record.Parents = sumSegments(record.Segments)
+	}
+	if !start {
+		record.AddDiagnostic(Warning, 0, "timing information not found")
+	} else if record.GetNumParents() == 0 {
+		record.AddDiagnostic(Warning, 0, "no timing rows found in timing information")
+	}
+	if record.FileType == "" {
+		record.FileType = MessageFile
+	}
+	record.Termination = termination.result(record.Progress)
+	record.Messages = messages.messages
+	return &record, nil
+}
+
+var rankPattern = regexp.MustCompile(`^\s*#\s*(\d+)\s+(\S+)\s+(.*)$`)
+
+// parseRank parses a row of MPP timing information per processor.
+func parseRank(line string, columns []string) (*Rank, bool) {
+	results := rankPattern.FindStringSubmatch(line)
+	if len(results) != 4 {
+		return nil, false
+	}
+	id, err := strconv.ParseInt(results[1], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	rank := Rank{Rank: id, Hostname: results[2]}
+	values := strings.Fields(results[3])
+	for i, column := range columns {
+		if i >= len(values) {
+			break
+		}
+		value, err := strconv.ParseFloat(values[i], 64)
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.Contains(column, "Avg"):
+			// Ratio to average is computed from values.
+		case strings.HasPrefix(column, "CPU"):
+			rank.CpuSec = value
+		case strings.HasPrefix(column, "Elapsed"), strings.HasPrefix(column, "Clock"):
+			rank.ClockSec = value
+		}
+	}
+	return &rank, true
+}
+
+// parseRankCategory returns the timing category named in title line of a table per processor
+// (e.g. "Element processing time per processor"), or empty if the table is of the totals.
+// Lines ending with a number (e.g. timing rows) are not titles.
+func parseRankCategory(title string, segments []*Segment) string {
+	fields := strings.Fields(title)
+	if len(fields) == 0 {
+		return ""
+	}
+	if _, err := strconv.ParseFloat(fields[len(fields)-1], 64); err == nil {
+		return ""
+	}
+	lower := strings.ToLower(title)
+	for i := len(segments) - 1; i >= 0; i-- {
+		for _, parent := range segments[i].Parents {
+			if strings.Contains(lower, strings.ToLower(parent.Name)) {
+				return parent.Name
+			}
+		}
+	}
+	return ""
+}
+
+// slice returns runes[start:end] as string, clamping the range to the length of runes.
+func slice(runes []rune, start, end int) string {
+	if end > len(runes) {
+		end = len(runes)
+	}
+	if start > end {
+		start = end
+	}
+	return string(runes[start:end])
+}
+
+func parseText(runes []rune, start, end int) string {
+	str := slice(runes, start, end)
+	return strings.Trim(str, " ")
+}
+
+func parseInt(runes []rune, start, end int) (int64, error) {
+	str := slice(runes, start, end)
+	str = strings.Trim(str, " ")
+	return strconv.ParseInt(str, 10, 64)
+}
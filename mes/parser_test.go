@@ -0,0 +1,186 @@
+package mes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// parseTestFile parses a file in testdata directory.
+func parseTestFile(t *testing.T, name string) *Record {
+	t.Helper()
+	fp, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fp.Close()
+	record, err := Parse(fp)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", name, err)
+	}
+	return record
+}
+
+func TestParse(t *testing.T) {
+	type row struct {
+		name                                       string
+		cpuSec, cpuPercent, clockSec, clockPercent float64
+		children                                   int
+	}
+	tests := []struct {
+		file        string
+		version     string
+		numCpus     int64
+		elapsedTime float64
+		segments    int
+		rows        []row
+		diagnostics []int
+	}{
+		{
+			// CPU(seconds) %CPU Clock(seconds) %Clock header of R7.
+			file:        "r7_smp_messag",
+			version:     "smp s R7.1.2",
+			numCpus:     4,
+			elapsedTime: 132,
+			segments:    1,
+			rows: []row{
+				{"Keyword Processing", 1.3389, 1.02, 1.3587, 1.02, 2},
+				{"Initialization", 1, 0.76, 1.01, 0.76, 0},
+				{"Element processing", 120, 91.4, 121, 91.4, 2},
+				{"Contact algorithm", 9, 6.82, 9, 6.8, 0},
+			},
+		},
+		{
+			// No header line, default columns are used.
+			file:        "r9_mpp_mes0000",
+			version:     "mpp s R9.3.0",
+			numCpus:     2,
+			elapsedTime: 101,
+			segments:    1,
+			rows: []row{
+				{"Keyword Processing", 2, 2, 2.1, 2.08, 0},
+				{"Element processing", 90, 90, 90.9, 90.09, 1},
+				{"Contact algorithm", 8, 8, 7.9, 7.83, 0},
+			},
+		},
+		{
+			// Elapsed(seconds) %Elapsed header and names longer than fixed widths of old releases.
+			file:        "r11_smp_messag",
+			version:     "smp d R11.1.0",
+			numCpus:     8,
+			elapsedTime: 50,
+			segments:    1,
+			rows: []row{
+				{"Keyword Processing", 1, 2, 1, 2, 0},
+				{"Element processing", 49, 98, 49, 98, 1},
+			},
+		},
+		{
+			// Clock columns only.
+			file:        "r15_smp_messag",
+			version:     "smp d R15.0.2",
+			numCpus:     16,
+			elapsedTime: 100,
+			segments:    1,
+			rows: []row{
+				{"Keyword Processing", 0, 0, 3, 3, 0},
+				{"Element processing", 0, 0, 97, 97, 0},
+			},
+		},
+		{
+			// Truncated rows are reported and skipped.
+			file:     "short_lines_messag",
+			version:  "smp s R9.3.0",
+			segments: 1,
+			rows: []row{
+				{"Keyword Processing", 1, 10, 1, 10, 0},
+				{"Element processing", 9, 90, 9, 90, 0},
+			},
+			diagnostics: []int{38, 39},
+		},
+		{
+			// Rows with values that are not numbers are reported and skipped.
+			file:        "bad_numbers_messag",
+			version:     "smp s R10.1.0",
+			numCpus:     1,
+			elapsedTime: 10,
+			segments:    1,
+			rows: []row{
+				{"Keyword Processing", 1, 10, 1, 10, 0},
+			},
+			diagnostics: []int{38, 39},
+		},
+		{
+			// Timings of blocks are summed, and percentages are recomputed.
+			file:        "multiple_blocks_messag",
+			version:     "smp s R12.0.0",
+			numCpus:     1,
+			elapsedTime: 42,
+			segments:    2,
+			rows: []row{
+				{"Keyword Processing", 3, 7.14, 3, 7.14, 0},
+				{"Element processing", 39, 92.86, 39, 92.86, 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			record := parseTestFile(t, tt.file)
+			if record.Version != tt.version {
+				t.Errorf("Version = %q, want %q", record.Version, tt.version)
+			}
+			if record.NumCpus != tt.numCpus {
+				t.Errorf("NumCpus = %d, want %d", record.NumCpus, tt.numCpus)
+			}
+			if record.ElapsedTime != tt.elapsedTime {
+				t.Errorf("ElapsedTime = %v, want %v", record.ElapsedTime, tt.elapsedTime)
+			}
+			if len(record.Segments) != tt.segments {
+				t.Errorf("len(Segments) = %d, want %d", len(record.Segments), tt.segments)
+			}
+			if len(record.Parents) != len(tt.rows) {
+				t.Fatalf("len(Parents) = %d, want %d", len(record.Parents), len(tt.rows))
+			}
+			for i, want := range tt.rows {
+				got := record.Parents[i]
+				if got.Name != want.name || got.CpuSec != want.cpuSec || got.CpuPercent != want.cpuPercent ||
+					got.ClockSec != want.clockSec || got.ClockPercent != want.clockPercent {
+					t.Errorf("Parents[%d] = %+v, want %+v", i, got.Data, want)
+				}
+				if len(got.Children) != want.children {
+					t.Errorf("len(Parents[%d].Children) = %d, want %d", i, len(got.Children), want.children)
+				}
+			}
+			if len(record.Diagnostics) != len(tt.diagnostics) {
+				t.Fatalf("Diagnostics = %v, want lines %v", record.Diagnostics, tt.diagnostics)
+			}
+			for i, line := range tt.diagnostics {
+				if record.Diagnostics[i].Line != line {
+					t.Errorf("Diagnostics[%d].Line = %d, want %d", i, record.Diagnostics[i].Line, line)
+				}
+			}
+		})
+	}
+}
+
+func TestParseChildren(t *testing.T) {
+	record := parseTestFile(t, "r11_smp_messag")
+	child := record.Parents[1].Children[0]
+	if child.Name != "Thick shells (shell formulation)" || child.ClockSec != 49 {
+		t.Errorf("child = %+v, want Thick shells (shell formulation) of 49 seconds", child.Data)
+	}
+}
+
+func TestParseWithoutTiming(t *testing.T) {
+	record, err := Parse(strings.NewReader(" Input file: model.k\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.InputFile != "model.k" || record.GetNumParents() != 0 {
+		t.Errorf("record = %+v, want input file model.k without timings", record)
+	}
+	if len(record.Diagnostics) != 1 || record.Diagnostics[0].Message != "timing information not found" {
+		t.Errorf("Diagnostics = %v, want timing information not found", record.Diagnostics)
+	}
+}
@@ -0,0 +1,106 @@
+package mes
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rankFilePattern matches base names of message files of MPP ranks (e.g. mes0000).
+var rankFilePattern = regexp.MustCompile(`^mes(\d+)$`)
+
+// compressionExtensions are extensions of compressed message files read by lsti (e.g. mes0001.gz).
+var compressionExtensions = []string{".gz", ".bz2", ".xz", ".zst"}
+
+// RankFileNumber returns the rank of message file name like mes0003 or mes0003.gz,
+// and reports false if name is not a message file of a rank.
+func RankFileNumber(name string) (int64, bool) {
+	base := filepath.Base(filepath.FromSlash(name))
+	ext := strings.ToLower(filepath.Ext(base))
+	for _, compression := range compressionExtensions {
+		if ext == compression {
+			base = base[:len(base)-len(ext)]
+			break
+		}
+	}
+	results := rankFilePattern.FindStringSubmatch(base)
+	if results == nil {
+		return 0, false
+	}
+	rank, err := strconv.ParseInt(results[1], 10, 64)
+	return rank, err == nil
+}
+
+// A RankFiles represents message files of ranks of an MPP run merged into a record.
+type RankFiles struct {
+	// Ranks are ranks of files found in ascending order, and Files are their paths.
+	Ranks []int64
+	Files []string
+
+	// Missing are ranks less than number of CPUs whose files are not found.
+	Missing []int64
+
+	// CpuSec and ClockSec are total timings of each file.
+	CpuSec, ClockSec []float64
+
+	// Parents are timings of each file.
+	Parents [][]*Parent
+}
+
+// GetImbalance returns load imbalance of total timings among rank files
+// for dataType (CpuSec or ClockSec).
+func (files *RankFiles) GetImbalance(dataType string) *Imbalance {
+	switch dataType {
+	case CpuSec:
+		return NewImbalance(files.Ranks, files.CpuSec)
+	case ClockSec:
+		return NewImbalance(files.Ranks, files.ClockSec)
+	}
+	return nil
+}
+
+// MergeRankFiles merges records of message files of ranks (mes0000, mes0001, ...) of an MPP run.
+// It returns a copy of the record of the lowest rank (rank 0 has the summary of the run)
+// with RankFiles, or nil if no records of rank files are given.
+func MergeRankFiles(records []*Record) *Record {
+	type rankRecord struct {
+		rank   int64
+		record *Record
+	}
+	var ranks []rankRecord
+	for _, record := range records {
+		if rank, ok := RankFileNumber(record.File); ok {
+			ranks = append(ranks, rankRecord{rank, record})
+		}
+	}
+	if len(ranks) == 0 {
+		return nil
+	}
+	sort.Slice(ranks, func(i, j int) bool { return ranks[i].rank < ranks[j].rank })
+
+	merged := *ranks[0].record
+	files := &RankFiles{}
+	found := make(map[int64]bool)
+	for _, r := range ranks {
+		files.Ranks = append(files.Ranks, r.rank)
+		files.Files = append(files.Files, r.record.File)
+		cpuSec, clockSec := 0.0, 0.0
+		r.record.ForEachParent(func(parent *Parent, _ int) {
+			cpuSec += parent.CpuSec
+			clockSec += parent.ClockSec
+		})
+		files.CpuSec = append(files.CpuSec, cpuSec)
+		files.ClockSec = append(files.ClockSec, clockSec)
+		files.Parents = append(files.Parents, r.record.Parents)
+		found[r.rank] = true
+	}
+	for rank := int64(0); rank < merged.NumCpus; rank++ {
+		if !found[rank] {
+			files.Missing = append(files.Missing, rank)
+		}
+	}
+	merged.RankFiles = files
+	return &merged
+}
@@ -0,0 +1,40 @@
+package mes
+
+import "testing"
+
+func TestRankFileNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		rank int64
+		ok   bool
+	}{
+		{"mes0000", 0, true},
+		{"run1/mes0003", 3, true},
+		{"run1/mes0001.gz", 1, true},
+		{"run1/mes0012.XZ", 12, true},
+		{"runs.tar.gz!run1/mes0002.zst", 2, true},
+		{"run1/messag", 0, false},
+		{"run1/mes0001.txt", 0, false},
+		{"run1/d3hsp.gz", 0, false},
+	}
+	for _, tt := range tests {
+		rank, ok := RankFileNumber(tt.name)
+		if rank != tt.rank || ok != tt.ok {
+			t.Errorf("RankFileNumber(%q) = %d, %v, want %d, %v", tt.name, rank, ok, tt.rank, tt.ok)
+		}
+	}
+}
+
+func TestMergeRankFiles(t *testing.T) {
+	records := []*Record{
+		{File: "run/mes0000", NumCpus: 3},
+		{File: "run/mes0001.gz", NumCpus: 3},
+	}
+	merged := MergeRankFiles(records)
+	if merged.File != "run/mes0000" || len(merged.RankFiles.Files) != 2 {
+		t.Errorf("MergeRankFiles() = %s with files %v, want run/mes0000 with 2 files", merged.File, merged.RankFiles.Files)
+	}
+	if missing := merged.RankFiles.Missing; len(missing) != 1 || missing[0] != 2 {
+		t.Errorf("Missing = %v, want [2]", missing)
+	}
+}
@@ -0,0 +1,90 @@
+package mes
+
+import "math"
+
+// A Segment represents a timing information block. A message file of restarted or
+// multi-stage run (e.g. dynamic relaxation then transient) has multiple segments.
+type Segment struct {
+	Parents     []*Parent
+	ElapsedTime float64
+}
+
+// AddParent adds parent data to segment.
+func (segment *Segment) AddParent(name string, cpuSec, cpuPercent, clockSec, clockPercent float64) *Parent {
+	parent := Parent{}
+	parent.Name = name
+	parent.CpuSec = cpuSec
+	parent.CpuPercent = cpuPercent
+	parent.ClockSec = clockSec
+	parent.ClockPercent = clockPercent
+	segment.Parents = append(segment.Parents, &parent)
+	return &parent
+}
+
+// GetSegment returns a copy of record that has timings and elapsed time of i-th segment only.
+func (record *Record) GetSegment(i int) *Record {
+	segment := *record
+	segment.Parents = record.Segments[i].Parents
+	segment.ElapsedTime = record.Segments[i].ElapsedTime
+	segment.Segment = i + 1
+	return &segment
+}
+
+// sumSegments returns parents and children summed by name over segments in order of appearance.
+// Percentages are recomputed from the sum of parents, and rounded to 2 decimals as printed.
+func sumSegments(segments []*Segment) []*Parent {
+	if len(segments) == 1 {
+		return segments[0].Parents
+	}
+
+	var parents []*Parent
+	index := make(map[string]*Parent)
+	add := func(sum, data *Data) {
+		sum.CpuSec += data.CpuSec
+		sum.ClockSec += data.ClockSec
+	}
+	for _, segment := range segments {
+		for _, p := range segment.Parents {
+			parent, ok := index[p.Name]
+			if !ok {
+				parent = &Parent{Data: Data{Name: p.Name}}
+				index[p.Name] = parent
+				parents = append(parents, parent)
+			}
+			add(&parent.Data, &p.Data)
+			for _, c := range p.Children {
+				var child *Child
+				for _, pc := range parent.Children {
+					if pc.Name == c.Name {
+						child = pc
+						break
+					}
+				}
+				if child == nil {
+					child = parent.AddChild(c.Name, 0, 0, 0, 0)
+				}
+				add(&child.Data, &c.Data)
+			}
+		}
+	}
+
+	total := Data{}
+	for _, parent := range parents {
+		add(&total, &parent.Data)
+	}
+	percent := func(data *Data) {
+		if total.CpuSec != 0 {
+			data.CpuPercent = math.Round(data.CpuSec/total.CpuSec*1e4) / 100
+		}
+		if total.ClockSec != 0 {
+			data.ClockPercent = math.Round(data.ClockSec/total.ClockSec*1e4) / 100
+		}
+	}
+	for _, parent := range parents {
+		percent(&parent.Data)
+		for _, child := range parent.Children {
+			percent(&child.Data)
+		}
+	}
+	return parents
+}
@@ -0,0 +1,71 @@
+package mes
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statusPattern matches status lines printed periodically during the solution
+// (e.g. "   1000 t 1.0000E-03 dt 1.00E-06 write d3plot file            05/13/19 14:21:50").
+var statusPattern = regexp.MustCompile(`^\s*(\d+)\s+t\s+(\S+)\s+dt\s+(\S+)\s*(.*?)\s*(\d\d/\d\d/\d\d\s+\d\d:\d\d:\d\d)?\s*$`)
+
+// statusDateLayout is the layout of date and time at the end of status lines.
+const statusDateLayout = "01/02/06 15:04:05"
+
+// A Status represents a status line of the solution.
+type Status struct {
+	Cycle    int64
+	Time     float64
+	TimeStep float64
+
+	// Event is what is done at the cycle (e.g. "write d3plot file").
+	Event string
+
+	// Date is the date and time printed in the line, or zero if not printed.
+	Date time.Time
+}
+
+// ParseStatus parses a status line of the solution.
+// It reports false if line is not a status line.
+func ParseStatus(line string) (*Status, bool) {
+	if !strings.Contains(line, " dt ") {
+		return nil, false
+	}
+	results := statusPattern.FindStringSubmatch(line)
+	if results == nil {
+		return nil, false
+	}
+	status := Status{Event: results[4]}
+	var err error
+	if status.Cycle, err = strconv.ParseInt(results[1], 10, 64); err != nil {
+		return nil, false
+	}
+	if status.Time, err = strconv.ParseFloat(results[2], 64); err != nil {
+		return nil, false
+	}
+	if status.TimeStep, err = strconv.ParseFloat(results[3], 64); err != nil {
+		return nil, false
+	}
+	if results[5] != "" {
+		date := strings.Join(strings.Fields(results[5]), " ")
+		status.Date, _ = time.ParseInLocation(statusDateLayout, date, time.Local)
+	}
+	return &status, true
+}
+
+// ParseTerminationTime parses termination time of control information in d3hsp file.
+// It reports false if line does not have termination time.
+func ParseTerminationTime(line string) (float64, bool) {
+	var record Record
+	if _, ok := parseD3hspLine(&record, line, 0); !ok || record.Control.TerminationTime == 0 {
+		return 0, false
+	}
+	return record.Control.TerminationTime, true
+}
+
+// IsTermination reports whether line is the banner of normal or error termination.
+func IsTermination(line string) bool {
+	return strings.Contains(line, "t e r m i n a t i o n")
+}
@@ -0,0 +1,49 @@
+package mes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProgress(t *testing.T) {
+	record := parseTestFile(t, "running_messag")
+	date := func(s string) time.Time {
+		d, err := time.ParseInLocation(statusDateLayout, s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	want := []Status{
+		{Cycle: 1, Time: 0, TimeStep: 2.5e-7, Event: "flush i/o buffers", Date: date("05/13/19 14:20:01")},
+		{Cycle: 500, Time: 1.25e-4, TimeStep: 2.5e-7, Event: "write d3plot file", Date: date("05/13/19 14:20:31")},
+		// Status without event nor date.
+		{Cycle: 1000, Time: 2.5e-4, TimeStep: 2.48e-7},
+		{Cycle: 1500, Time: 3.74e-4, TimeStep: 2.48e-7, Event: "write d3plot file", Date: date("05/13/19 14:21:50")},
+	}
+	if len(record.Progress) != len(want) {
+		t.Fatalf("len(Progress) = %d, want %d", len(record.Progress), len(want))
+	}
+	for i, w := range want {
+		got := record.Progress[i]
+		if got.Cycle != w.Cycle || got.Time != w.Time || got.TimeStep != w.TimeStep || got.Event != w.Event || !got.Date.Equal(w.Date) {
+			t.Errorf("Progress[%d] = %+v, want %+v", i, *got, w)
+		}
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+	}{
+		{"  1000 t 1.0000E-03 dt 1.00E-06 write d3plot file            03/14/19 10:23:30", true},
+		{"     contact dt  of 2.4E-07 is smaller than the solid dt", false},
+		{"  1000 t 1.0.0E-03 dt 1.00E-06", false},
+	}
+	for _, tt := range tests {
+		if _, ok := ParseStatus(tt.line); ok != tt.ok {
+			t.Errorf("ParseStatus(%q) reports %v, want %v", tt.line, ok, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package mes
+
+// Metric names select a value of Data.
+const (
+	CpuSec       = "cpusec"
+	CpuPercent   = "pcpu"
+	ClockSec     = "clocksec"
+	ClockPercent = "pclock"
+)
+
+// A Data represents the timing information parsed from LS-DYNA message file.
+type Data struct {
+	Name                                       string
+	CpuSec, CpuPercent, ClockSec, ClockPercent float64
+}
+
+// GetValue returns value used for aggregation.
+// dataType is one of the metric names (e.g. CpuSec), otherwise 0 is returned.
+func (data *Data) GetValue(dataType string) float64 {
+	switch dataType {
+	case CpuSec:
+		return data.CpuSec
+	case CpuPercent:
+		return data.CpuPercent
+	case ClockSec:
+		return data.ClockSec
+	case ClockPercent:
+		return data.ClockPercent
+	}
+	return 0.0
+}
+
+// A Child represents the child information (e.g. Solids, Shells).
+type Child struct {
+	Data
+}
+
+// Parent represents the parent information (e.g. Keyword Processing).
+type Parent struct {
+	Data
+	Children []*Child
+}
+
+// AddChild adds child data to parent's children.
+func (parent *Parent) AddChild(name string, cpuSec, cpuPercent, clockSec, clockPercent float64) *Child {
+	child := Child{}
+	child.Name = name
+	child.CpuSec = cpuSec
+	child.CpuPercent = cpuPercent
+	child.ClockSec = clockSec
+	child.ClockPercent = clockPercent
+	parent.Children = append(parent.Children, &child)
+	return &child
+}
+
+// GetNumChildren returns the number of Children in this parent data.
+func (parent *Parent) GetNumChildren() int {
+	return len(parent.Children)
+}
+
+// ForEachChildren executes callback function for each child in this parent data.
+func (parent *Parent) ForEachChildren(cb func(*Child, int)) {
+	for i, child := range parent.Children {
+		cb(child, i)
+	}
+}
+
+// A Rank represents the timing information of a processor in MPP execution.
+type Rank struct {
+	Rank             int64
+	Hostname         string
+	CpuSec, ClockSec float64
+
+	// Category is the timing category (e.g. Element processing) of the table per processor,
+	// or empty if the table is of the totals.
+	Category string
+}
+
+// GetValue returns value used for aggregation.
+// dataType is CpuSec or ClockSec, otherwise 0 is returned.
+func (rank *Rank) GetValue(dataType string) float64 {
+	switch dataType {
+	case CpuSec:
+		return rank.CpuSec
+	case ClockSec:
+		return rank.ClockSec
+	}
+	return 0.0
+}
+
+// File types of Record.FileType.
+const (
+	MessageFile = "messag"
+	D3hspFile   = "d3hsp"
+)
+
+// A Model represents the model size printed in d3hsp file.
+type Model struct {
+	NumNodes, NumParts                             int64
+	NumSolids, NumShells, NumThickShells, NumBeams int64
+}
+
+// A Control represents the time step controls printed in d3hsp file.
+type Control struct {
+	TerminationTime float64
+
+	// TimeStepScale is the scale factor for computed time step (TSSFAC).
+	TimeStepScale float64
+
+	// MassScaledTimeStep is the time step size for mass scaled solution (DT2MS).
+	MassScaledTimeStep float64
+}
+
+// A MassScaling represents the mass added by mass scaling.
+type MassScaling struct {
+	AddedMass, PhysicalMass float64
+
+	// Ratio is the ratio of added mass to physical mass.
+	Ratio float64
+}
+
+// Record represents the data set parsed from a LS-DYNA message file or d3hsp file.
+type Record struct {
+	File string
+
+	// Path is the absolute path of the file, set by ParseFile and ParseReader.
+	// Unlike File, it does not depend on the working directory and options.
+	Path string
+
+	// Hash is the SHA-256 of the file content in hex, set by ParseFile and ParseReader.
+	Hash string
+
+	// FileType is MessageFile or D3hspFile.
+	FileType string
+
+	Version                                     string
+	Revision                                    int64
+	Date, Time                                  string
+	LicensedTo, IssuedBy                        string
+	Platform, Os, Compiler, Hostname, Precision string
+	SvnVersion                                  int64
+
+	InputFile string
+
+	NumCpus           int64
+	NormalTermination bool
+	ElapsedTime       float64
+
+	// Termination classifies how the run terminated, not only normal termination.
+	Termination Termination
+
+	// Parents are timings summed over segments.
+	Parents []*Parent
+
+	// Segments are timing information blocks in order of appearance.
+	Segments []*Segment
+
+	// Segment is the 1-based index of segment if the record is a copy for a segment
+	// (see GetSegment), otherwise 0.
+	Segment int
+
+	// Ranks are timings per processor of MPP execution.
+	Ranks []*Rank
+
+	// RankFiles are message files of ranks merged into this record (see MergeRankFiles),
+	// or nil if not merged.
+	RankFiles *RankFiles
+
+	// Progress is the history of status lines of the solution.
+	Progress []*Status
+
+	// Model, Control and MassScaling are parsed from d3hsp file only.
+	Model       Model
+	Control     Control
+	MassScaling MassScaling
+
+	// Memory is requested and required memory.
+	Memory Memory
+
+	// Messages are warning and error messages printed by LS-DYNA in order of first occurrence.
+	Messages []*Message
+
+	// Diagnostics are problems found while parsing.
+	Diagnostics []*Diagnostic
+}
+
+// GetNumParents returns the number of parents in this record.
+func (record *Record) GetNumParents() int {
+	return len(record.Parents)
+}
+
+// GetNumChildren returns the number of Children in this record.
+func (record *Record) GetNumChildren() int {
+	numChildren := 0
+	record.ForEachParent(func(parent *Parent, i int) {
+		numChildren += parent.GetNumChildren()
+	})
+	return numChildren
+}
+
+// GetNumData returns the number of data (num parents + num Children) in this record.
+func (record *Record) GetNumData() int {
+	numData := 0
+	record.ForEachParent(func(parent *Parent, i int) {
+		numData++
+		numData += parent.GetNumChildren()
+	})
+	return numData
+}
+
+// ForEachParent executes callback function for each parent.
+func (record *Record) ForEachParent(cb func(*Parent, int)) {
+	for i, parent := range record.Parents {
+		cb(parent, i)
+	}
+}
+
+// ForEachData executes callback function for each data.
+func (record *Record) ForEachData(cb func(interface{}, int)) {
+	count := 0
+	record.ForEachParent(func(parent *Parent, _ int) {
+		cb(parent, count)
+		count++
+		parent.ForEachChildren(func(child *Child, _ int) {
+			cb(child, count)
+			count++
+		})
+	})
+}
+
+// ForEachChild executes callback function for each child.
+func (record *Record) ForEachChild(cb func(interface{}, int)) {
+	count := 0
+	record.ForEachParent(func(parent *Parent, _ int) {
+		cb(parent, count)
+		count++
+		parent.ForEachChildren(func(child *Child, _ int) {
+			cb(child, count)
+			count++
+		})
+	})
+}
+
+// AddParent adds parent data to record.
+func (record *Record) AddParent(name string, cpuSec, cpuPercent, clockSec, clockPercent float64) *Parent {
+	parent := Parent{}
+	parent.Name = name
+	parent.CpuSec = cpuSec
+	parent.CpuPercent = cpuPercent
+	parent.ClockSec = clockSec
+	parent.ClockPercent = clockPercent
+	record.Parents = append(record.Parents, &parent)
+	return &parent
+}
+
+// GetImbalance returns load imbalance of the totals among ranks for dataType (CpuSec or ClockSec)
+// from the table per processor, or from total timings of rank files if merged (see MergeRankFiles).
+// It returns nil if record has no ranks.
+func (record *Record) GetImbalance(dataType string) *Imbalance {
+	var ids []int64
+	var values []float64
+	for _, rank := range record.Ranks {
+		if rank.Category == "" {
+			ids = append(ids, rank.Rank)
+			values = append(values, rank.GetValue(dataType))
+		}
+	}
+	if len(values) == 0 && record.RankFiles != nil {
+		return record.RankFiles.GetImbalance(dataType)
+	}
+	return NewImbalance(ids, values)
+}
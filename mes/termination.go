@@ -0,0 +1,203 @@
+package mes
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Termination classes.
+const (
+	NormalTermination = "normal"
+	ErrorTermination  = "error"
+	Stopped           = "stopped"
+	OutOfMemory       = "outOfMemory"
+	NegativeVolume    = "negativeVolume"
+	LicenseFailure    = "licenseFailure"
+	Incomplete        = "incomplete"
+)
+
+// TerminationClasses are all termination classes.
+var TerminationClasses = []string{
+	NormalTermination, ErrorTermination, Stopped, OutOfMemory, NegativeVolume, LicenseFailure, Incomplete,
+}
+
+// A Termination represents how a run terminated.
+type Termination struct {
+	// Class is one of termination classes (e.g. NormalTermination).
+	Class string
+
+	// Message is the line that shows the reason of termination, and Line is its line number.
+	Message string
+	Line    int
+
+	// Cycle and Time are the cycle and problem time reached.
+	Cycle int64
+	Time  float64
+}
+
+// senseSwitchPattern matches lines of sense switch sw1 (terminate) or signal to stop.
+var senseSwitchPattern = regexp.MustCompile(`(?i)\bsw1\b|sense switch|sigterm|signal 15`)
+
+// problemPattern matches footer lines like "Problem time       =    2.0000E-03".
+var problemPattern = regexp.MustCompile(`^\s*Problem (time|cycle)\s*=\s*(\S+)`)
+
+// classifyLine returns termination class caused by the message of line, or "" if not a cause.
+func classifyLine(line string) string {
+	lower := strings.ToLower(line)
+	switch {
+	case senseSwitchPattern.MatchString(line):
+		return Stopped
+	case strings.Contains(lower, "negative volume"):
+		return NegativeVolume
+	case strings.Contains(lower, "out of memory"), strings.Contains(lower, "insufficient memory"),
+		strings.Contains(lower, "not enough memory"), strings.Contains(lower, "memory allocation fail"):
+		return OutOfMemory
+	case strings.Contains(lower, "license") && !strings.Contains(lower, "licensed to") &&
+		(strings.Contains(lower, "fail") || strings.Contains(lower, "error") || strings.Contains(lower, "expire") ||
+			strings.Contains(lower, "denied") || strings.Contains(lower, "unable") || strings.Contains(lower, "not available")):
+		return LicenseFailure
+	}
+	return ""
+}
+
+// causeWindow is the number of lines before and after the error termination banner
+// searched for the cause of termination outside of error messages.
+const causeWindow = 20
+
+// A terminationParser collects termination of a record line by line.
+type terminationParser struct {
+	cause, stop, errorLine, banner Termination
+	normal, failed                 bool
+	cycle                          int64
+	time                           float64
+	hasProblem                     bool
+
+	// nearby are causes found outside of messages within causeWindow lines of the current line
+	// or of the error termination banner, and bannerLine is the line number of the banner.
+	nearby     []Termination
+	bannerLine int
+
+	// severity is the severity of the message being printed until a blank line.
+	severity string
+}
+
+// parseLine parses line of lineNumber.
+func (p *terminationParser) parseLine(line string, lineNumber int) {
+	message := strings.TrimSpace(line)
+	if strings.Contains(line, "N o r m a l    t e r m i n a t i o n") {
+		p.normal = true
+		if p.banner.Message == "" {
+			p.banner = Termination{Message: message, Line: lineNumber}
+		}
+		return
+	}
+	if strings.Contains(line, "E r r o r   t e r m i n a t i o n") {
+		p.failed = true
+		p.banner = Termination{Message: message, Line: lineNumber}
+		p.bannerLine = lineNumber
+		return
+	}
+	if strings.Contains(line, "termination time reached") {
+		p.banner = Termination{Message: message, Line: lineNumber}
+		return
+	}
+	if results := problemPattern.FindStringSubmatch(line); results != nil {
+		p.hasProblem = true
+		if results[1] == "time" {
+			p.time, _ = strconv.ParseFloat(results[2], 64)
+		} else {
+			p.cycle, _ = strconv.ParseInt(results[2], 10, 64)
+		}
+		return
+	}
+
+	// A message continues from "*** Warning" or "*** Error" line until a blank line.
+	if message == "" {
+		p.severity = ""
+		return
+	}
+	if results := messagePattern.FindStringSubmatch(line); results != nil {
+		p.severity = Warning
+		if results[1] == "Error" {
+			p.severity = Error
+		}
+		if p.severity == Error && p.errorLine.Message == "" {
+			p.errorLine = Termination{Message: message, Line: lineNumber}
+		}
+	}
+
+	class := classifyLine(line)
+	if class == "" {
+		return
+	}
+	cause := Termination{Class: class, Message: message, Line: lineNumber}
+	switch {
+	case p.severity == Warning:
+		// Warnings (e.g. negative volume deleted by erosion) do not terminate the run.
+	case p.severity == Error:
+		// The first cause is the reason, following lines are consequences.
+		if p.cause.Class == "" {
+			p.cause = cause
+		}
+	case class == Stopped:
+		if p.stop.Class == "" {
+			p.stop = cause
+		}
+	case !p.failed:
+		for len(p.nearby) > 0 && p.nearby[0].Line < lineNumber-causeWindow {
+			p.nearby = p.nearby[1:]
+		}
+		p.nearby = append(p.nearby, cause)
+	case lineNumber <= p.bannerLine+causeWindow:
+		p.nearby = append(p.nearby, cause)
+	}
+}
+
+// result returns termination classified from parsed lines and progress.
+// A run without normal or error termination banner is incomplete (e.g. still running).
+// Stop by sense switch or signal is reported even if it is followed by normal termination,
+// and causes in error messages or near the error termination banner only if the run failed.
+func (p *terminationParser) result(progress []*Status) Termination {
+	var t Termination
+	switch {
+	case !p.normal && !p.failed:
+		t = Termination{Class: Incomplete}
+	case p.stop.Class != "":
+		t = p.stop
+	case p.normal:
+		t = p.banner
+		t.Class = NormalTermination
+	case p.cause.Class != "":
+		t = p.cause
+	case p.nearbyCause().Class != "":
+		t = p.nearbyCause()
+	default:
+		t = p.banner
+		if p.errorLine.Message != "" {
+			t = p.errorLine
+		}
+		t.Class = ErrorTermination
+	}
+
+	if n := len(progress); n > 0 {
+		t.Cycle = progress[n-1].Cycle
+		t.Time = progress[n-1].Time
+	}
+	if p.hasProblem {
+		t.Cycle = p.cycle
+		t.Time = p.time
+	}
+	return t
+}
+
+// nearbyCause returns the first cause within causeWindow lines of the error termination banner,
+// or empty termination if not found.
+func (p *terminationParser) nearbyCause() Termination {
+	for _, cause := range p.nearby {
+		if cause.Line >= p.bannerLine-causeWindow {
+			return cause
+		}
+	}
+	return Termination{}
+}
@@ -0,0 +1,86 @@
+package mes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTermination(t *testing.T) {
+	const (
+		normal = " N o r m a l    t e r m i n a t i o n                   03/14/2019 10:24:45"
+		failed = " E r r o r   t e r m i n a t i o n                     03/14/2019 10:24:45"
+	)
+	tests := []struct {
+		name    string
+		lines   []string
+		class   string
+		message string
+	}{
+		{"normal", []string{normal}, NormalTermination, normal[1:]},
+		{"still running", []string{"  1000 t 1.0000E-03 dt 1.00E-06 write d3plot file"}, Incomplete, ""},
+		{
+			"warning of still running",
+			[]string{" *** Warning 40509 (SOL+509)", "     negative volume in solid element # 12", "", "  1000 t 1.0000E-03 dt 1.00E-06 write d3plot file"},
+			Incomplete, "",
+		},
+		{
+			"warning of truncated",
+			[]string{" *** Warning 20007 (MEM+7)", "     insufficient memory for contact, expanding", ""},
+			Incomplete, "",
+		},
+		{
+			"warning before error",
+			[]string{" *** Warning 40509 (SOL+509)", "     negative volume in solid element # 12", "", " *** Error 10246 (SOL+246)", "     out of memory", "", failed},
+			OutOfMemory, "out of memory",
+		},
+		{
+			"negative volume error",
+			[]string{" *** Error 40509 (SOL+509)", "     negative volume in solid element # 12", "", failed},
+			NegativeVolume, "negative volume in solid element # 12",
+		},
+		{
+			"cause near banner",
+			[]string{" License checkout failed for feature MPPDYNA", failed},
+			LicenseFailure, "License checkout failed for feature MPPDYNA",
+		},
+		{
+			"license failure without banner",
+			[]string{" License checkout failed for feature MPPDYNA"},
+			Incomplete, "",
+		},
+		{
+			"error without cause",
+			[]string{" *** Error 10000 (KEY+1)", "     keyword not recognized", "", failed},
+			ErrorTermination, "*** Error 10000 (KEY+1)",
+		},
+		{
+			"sense switch",
+			[]string{" sw1. received, terminating", normal},
+			Stopped, "sw1. received, terminating",
+		},
+	}
+	for _, tt := range tests {
+		record, err := Parse(strings.NewReader(strings.Join(tt.lines, "\n") + "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := record.Termination; got.Class != tt.class || got.Message != tt.message {
+			t.Errorf("%s: Termination = %q %q, want %q %q", tt.name, got.Class, got.Message, tt.class, tt.message)
+		}
+	}
+}
+
+func TestTerminationCauseFarFromBanner(t *testing.T) {
+	lines := []string{" License checkout failed for feature MPPDYNA"}
+	for i := 0; i < causeWindow+1; i++ {
+		lines = append(lines, "  1000 t 1.0000E-03 dt 1.00E-06 write d3plot file")
+	}
+	lines = append(lines, " E r r o r   t e r m i n a t i o n")
+	record, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Termination.Class != ErrorTermination {
+		t.Errorf("Termination.Class = %q, want %q", record.Termination.Class, ErrorTermination)
+	}
+}
@@ -0,0 +1,103 @@
+package mes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultColumns are the columns of timing information block used
+// if the header line is not recognized.
+var defaultColumns = []string{CpuSec, CpuPercent, ClockSec, ClockPercent}
+
+// A timingLayout represents columns of timing information block.
+type timingLayout struct {
+	// columns are metric names (e.g. CpuSec) in order of appearance.
+	columns []string
+
+	// parentIndent is the indent of parent rows, children are indented more.
+	parentIndent int
+}
+
+// detectTimingLayout detects columns from the header line of timing information block
+// (e.g. "CPU(seconds)   %CPU  Clock(seconds) %Clock").
+// If line is not a header line, it returns default layout and false.
+func detectTimingLayout(line string) (*timingLayout, bool) {
+	var columns []string
+	for _, field := range strings.Fields(strings.ToLower(line)) {
+		switch {
+		case strings.HasPrefix(field, "cpu("):
+			columns = append(columns, CpuSec)
+		case field == "%cpu":
+			columns = append(columns, CpuPercent)
+		case strings.HasPrefix(field, "clock("), strings.HasPrefix(field, "elapsed("):
+			columns = append(columns, ClockSec)
+		case field == "%clock", field == "%elapsed":
+			columns = append(columns, ClockPercent)
+		default:
+			// Not a header line, because header has only column names.
+			return &timingLayout{columns: defaultColumns, parentIndent: -1}, false
+		}
+	}
+	if len(columns) == 0 {
+		return &timingLayout{columns: defaultColumns, parentIndent: -1}, false
+	}
+	return &timingLayout{columns: columns, parentIndent: -1}, true
+}
+
+// A timingRow represents a row of timing information block.
+type timingRow struct {
+	Data
+	IsParent bool
+}
+
+// parseRow tokenizes a row of timing information block
+// (e.g. "  Keyword Processing ... 1.3389E+00    1.02    1.3587E+00    1.02").
+// The name is followed by a value for each column.
+func (layout *timingLayout) parseRow(line string) (*timingRow, error) {
+	fields := strings.Fields(line)
+	n := len(layout.columns)
+	if len(fields) <= n {
+		return nil, fmt.Errorf("expected name and %d values, found %d fields", n, len(fields))
+	}
+
+	row := timingRow{}
+	values := fields[len(fields)-n:]
+	for i, column := range layout.columns {
+		value, err := strconv.ParseFloat(values[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s", values[i], column)
+		}
+		switch column {
+		case CpuSec:
+			row.CpuSec = value
+		case CpuPercent:
+			row.CpuPercent = value
+		case ClockSec:
+			row.ClockSec = value
+		case ClockPercent:
+			row.ClockPercent = value
+		}
+	}
+
+	name := strings.Join(fields[:len(fields)-n], " ")
+	row.Name = strings.TrimRight(strings.TrimRight(name, "."), " ")
+	if row.Name == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+
+	// The first row is a parent, and rows indented more than it are children.
+	indent := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+	if layout.parentIndent < 0 {
+		layout.parentIndent = indent
+	}
+	row.IsParent = indent <= layout.parentIndent
+	return &row, nil
+}
+
+// isSeparator reports whether line is a separator line of timing information block.
+func isSeparator(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) >= 10 && strings.Trim(trimmed, "-") == ""
+}
@@ -0,0 +1,59 @@
+package mes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectTimingLayout(t *testing.T) {
+	tests := []struct {
+		line     string
+		columns  []string
+		isHeader bool
+	}{
+		{"                        CPU(seconds)   %CPU  Clock(seconds) %Clock", []string{CpuSec, CpuPercent, ClockSec, ClockPercent}, true},
+		{"                   CPU(seconds)   %CPU  Elapsed(seconds) %Elapsed", []string{CpuSec, CpuPercent, ClockSec, ClockPercent}, true},
+		{"                                Clock(seconds) %Clock", []string{ClockSec, ClockPercent}, true},
+		{"  Keyword Processing ... 1.3389E+00    1.02  1.3587E+00    1.02", defaultColumns, false},
+		{"  ----------------------------------------------------------------", defaultColumns, false},
+	}
+	for _, tt := range tests {
+		layout, isHeader := detectTimingLayout(tt.line)
+		if !reflect.DeepEqual(layout.columns, tt.columns) || isHeader != tt.isHeader {
+			t.Errorf("detectTimingLayout(%q) = %v, %v, want %v, %v", tt.line, layout.columns, isHeader, tt.columns, tt.isHeader)
+		}
+	}
+}
+
+func TestParseRow(t *testing.T) {
+	tests := []struct {
+		line     string
+		want     Data
+		isParent bool
+		wantErr  bool
+	}{
+		{"  Keyword Processing ... 1.3389E+00    1.02  1.3587E+00    1.02", Data{"Keyword Processing", 1.3389, 1.02, 1.3587, 1.02}, true, false},
+		{"    KW Reading ......... 1.2000E+00    0.91  1.2100E+00    0.91", Data{"KW Reading", 1.2, 0.91, 1.21, 0.91}, false, false},
+		{"  Element processing 1.0E+02 90.00 1.0E+02 90.00", Data{"Element processing", 100, 90, 100, 90}, true, false},
+		{"  Initialization ....... 1.0000E+00", Data{}, false, true},
+		{"  Contact algorithm .... 1.0000E+00    1.2.3  1.0000E+00    1.00", Data{}, false, true},
+		{"  ............ 1.0000E+00    1.00  1.0000E+00    1.00", Data{}, false, true},
+	}
+	for _, tt := range tests {
+		layout := &timingLayout{columns: defaultColumns, parentIndent: 2}
+		row, err := layout.parseRow(tt.line)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseRow(%q) = %+v, want error", tt.line, row)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRow(%q): %v", tt.line, err)
+			continue
+		}
+		if row.Data != tt.want || row.IsParent != tt.isParent {
+			t.Errorf("parseRow(%q) = %+v, %v, want %+v, %v", tt.line, row.Data, row.IsParent, tt.want, tt.isParent)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"lsti/mes"
+)
+
+// WriteMessages writes warning and error messages of records to stdout.
+func (cli *CLI) WriteMessages(records []*mes.Record) error {
+	return cli.WriteData(cli.NormalizeMessages(records))
+}
+
+// NormalizeMessages normalizes warning and error messages of records for json output.
+// Each message code of each record becomes a record that has only properties.
+// If "--severity" option is specified, only messages of the severity are included.
+func (cli *CLI) NormalizeMessages(records []*mes.Record) []*RecordData {
+	var ds []*RecordData
+	for _, record := range records {
+		for _, message := range record.Messages {
+			if opts.Messages.Severity != "" && message.Severity != opts.Messages.Severity {
+				continue
+			}
+			ds = append(ds, &RecordData{
+				Properties: []*JsonData{
+					{Name: "file", Value: record.File},
+					{Name: "severity", Value: message.Severity},
+					{Name: "code", Value: message.Code},
+					{Name: "count", Value: message.Count},
+					{Name: "line", Value: message.Line},
+					{Name: "text", Value: message.Text},
+				},
+				Timings: make([]*TimingData, 0),
+			})
+		}
+	}
+	return ds
+}
@@ -1,206 +1,170 @@
 package main
 
 import (
-	"bufio"
-	"fmt"
-	"os"
+	"io"
+	"io/ioutil"
 	"path/filepath"
-	"regexp"
-	"sort"
-	"strconv"
-	"strings"
+	"runtime"
+
+	"lsti/mes"
 )
 
-// ParseMessageFiles parses LS-DYNA message files (e.g. messag, mes****) and return records.
-func (cli *CLI) ParseMessageFiles(files []string) ([]*Record, error) {
-	sort.Strings(files)
-	var records []*Record
-	for _, file := range files {
-		record, err := cli.ParseMessageFile(file)
-		if err != nil {
-			fmt.Fprintln(cli.errStream, err)
+// ParseMessageFiles parses LS-DYNA message files (e.g. messag, mes****) and return records
+// with diagnostics found in them. Files failed to parse are reported as error diagnostics.
+func (cli *CLI) ParseMessageFiles(files []string) ([]*mes.Record, []*mes.Diagnostic) {
+	var records []*mes.Record
+	var diagnostics []*mes.Diagnostic
+	cli.EachMessageFile(files, func(record *mes.Record, ds []*mes.Diagnostic) {
+		diagnostics = append(diagnostics, ds...)
+		if record != nil {
+			records = append(records, record)
 		}
-		records = append(records, record)
-	}
-	return records, nil
+	})
+	return records, diagnostics
 }
 
-// ParseMessageFile parses LS-DYNA message file (e.g. messag, mes****) and return record.
-func (cli *CLI) ParseMessageFile(file string) (*Record, error) {
-	fp, err := os.Open(filepath.FromSlash(file))
-	if err != nil {
-		return nil, err
+// EachMessageFile parses files concurrently by "-j, --jobs" workers, and executes callback
+// function for each file in order of files with the record (nil if failed or excluded by
+// "--termination" option) and diagnostics.
+// Archives are expanded to their members, and at most as many records as workers
+// (and rank files of a run with "--merge-ranks") are held waiting for callback.
+func (cli *CLI) EachMessageFile(files []string, cb func(*mes.Record, []*mes.Diagnostic)) {
+	jobs := opts.Out.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
 	}
-	defer fp.Close()
-
-	// Translate file path.
-	abs, err := filepath.Abs(file)
-	if err == nil {
-		if opts.Out.Abs {
-			file = abs
-		} else if opts.Out.Relative != "" {
-			rel, err := filepath.Rel(filepath.FromSlash(opts.Out.Relative), abs)
-			if err == nil {
-				file = rel
-			}
-		}
+	parseFile := cli.parse
+	if parseFile == nil {
+		parseFile = cli.ParseMessageFile
 	}
 
-	record := Record{File: file}
-	scanner := bufio.NewScanner(fp)
-	start := false
-	end := false
-	count := 0
-	const (
-		SMP = "smp"
-		MPP = "mpp"
-	)
-	var currentParent *Parent
-	var moduleType string
-	for scanner.Scan() {
-		line := scanner.Text()
+	type result struct {
+		record      *mes.Record
+		diagnostics []*mes.Diagnostic
+	}
 
-		// Search for header information.
-		if !start {
-			if strings.Contains(line, "Version : ") {
-				record.Version = parseText([]rune(line), 18, 34)
-				record.Date = parseText([]rune(line), 34, 55)
-				if strings.Contains(record.Version, "smp") {
-					moduleType = SMP
-				} else if strings.Contains(record.Version, "mpp") {
-					moduleType = MPP
-				}
-				continue
-			}
-			if strings.Contains(line, "Revision: ") {
-				record.Revision, _ = parseInt([]rune(line), 18, 34)
-				record.Time = parseText([]rune(line), 34, 55)
-				continue
-			}
-			if strings.Contains(line, "Licensed to: ") {
-				record.LicensedTo = parseText([]rune(line), 21, 55)
-				continue
-			}
-			if strings.Contains(line, "Issued by  : ") {
-				record.IssuedBy = parseText([]rune(line), 21, 55)
-				continue
-			}
-			if strings.Contains(line, "Platform   : ") {
-				record.Platform = parseText([]rune(line), 21, 55)
-				continue
-			}
-			if strings.Contains(line, "OS Level   : ") {
-				record.Os = parseText([]rune(line), 21, 55)
-				continue
-			}
-			if strings.Contains(line, "Compiler   : ") {
-				record.Compiler = parseText([]rune(line), 21, 55)
-				continue
-			}
-			if strings.Contains(line, "Hostname   : ") {
-				record.Hostname = parseText([]rune(line), 21, 55)
-				continue
-			}
-			if strings.Contains(line, "Precision  : ") {
-				record.Precision = parseText([]rune(line), 21, 55)
-				continue
-			}
-			if strings.Contains(line, "SVN Version: ") {
-				record.SvnVersion, _ = parseInt([]rune(line), 21, 55)
+	// Results are queued in order of files. Each result holds a slot of the semaphore
+	// until it is consumed, so that at most jobs files are parsed or waiting for callback.
+	queue := make(chan chan *result, jobs)
+	semaphore := make(chan struct{}, jobs)
+	fail := func(file, severity, message string) {
+		semaphore <- struct{}{}
+		ch := make(chan *result, 1)
+		ch <- &result{diagnostics: []*mes.Diagnostic{{File: file, Severity: severity, Message: message}}}
+		queue <- ch
+	}
+	parse := func(src *source) {
+		semaphore <- struct{}{}
+		ch := make(chan *result, 1)
+		queue <- ch
+		go func() {
+			record, err := parseFile(src)
+			if err != nil {
+				ch <- &result{diagnostics: []*mes.Diagnostic{{File: src.Name, Severity: mes.Error, Message: err.Error()}}}
+				return
+			}
+			ch <- &result{record: record, diagnostics: record.Diagnostics}
+		}()
+	}
+	go func() {
+		for _, file := range files {
+			if file == stdinName {
+				parse(&source{Name: file, Open: func() (io.ReadCloser, error) { return ioutil.NopCloser(cli.inStream), nil }})
 				continue
 			}
-			if strings.Contains(line, "Input file: ") {
-				record.InputFile = parseText([]rune(line), 13, 84)
+			archive, pattern, ok := splitArchivePath(file)
+			if !ok && !isArchive(file) {
+				file := file
+				parse(&source{Name: file, Open: func() (io.ReadCloser, error) { return openFile(file) }})
 				continue
 			}
-			if moduleType == MPP && strings.HasPrefix(line, " MPP execution with") {
-				record.NumCpus, _ = parseInt([]rune(line), 19, 27)
-				continue
+			found := false
+			err := eachArchiveMember(archive, pattern, func(src *source) {
+				found = true
+				parse(src)
+			})
+			if err != nil {
+				fail(archive, mes.Error, err.Error())
+			} else if !found {
+				fail(file, mes.Warning, "no message files found in archive")
 			}
 		}
+		close(queue)
+	}()
 
-		// Search for timing information block.
-		if strings.HasPrefix(line, " T i m i n g   i n f o r m a t i o n") {
-			start = true
-			continue
-		}
-		if !start {
-			continue
-		}
-
-		// Skip 2 header lines.
-		count++
-		if count <= 2 {
-			continue
+	emit := func(record *mes.Record, diagnostics []*mes.Diagnostic) {
+		// Records excluded by "--termination" option are not passed, but their diagnostics are.
+		if record != nil && !includesTermination(record) {
+			record = nil
 		}
-
-		// If timing information block ends, stop reading.
-		if strings.Contains(line, "-----------------------") {
-			end = true
-			continue
+		// With "--segments each", each segment is passed as a record.
+		if record != nil && opts.Out.Segments == EachSegments && len(record.Segments) > 1 {
+			for i := range record.Segments {
+				if i == 0 {
+					cb(record.GetSegment(i), diagnostics)
+				} else {
+					cb(record.GetSegment(i), nil)
+				}
+			}
+			return
 		}
+		cb(record, diagnostics)
+	}
 
-		// Parse timing information.
-		if start && !end {
-			isParent := !strings.HasPrefix(line, "    ")
-			runes := []rune(line)
-			name := parseName(runes, 0, 25)
-			cpuSec, _ := parseFloat(runes, 25, 36)
-			cpuPercent, _ := parseFloat(runes, 36, 44)
-			clockSec, _ := parseFloat(runes, 44, 58)
-			clockPercent, _ := parseFloat(runes, 58, 66)
-			if isParent {
-				// Parent
-				currentParent = record.AddParent(name, cpuSec, cpuPercent, clockSec, clockPercent)
-			} else {
-				// Child
-				currentParent.AddChild(name, cpuSec, cpuPercent, clockSec, clockPercent)
-			}
+	// With "--merge-ranks", records of rank files (mes0000, mes0001, ...) in the same directory
+	// are merged into a record. They are consecutive because files are sorted.
+	var group []*mes.Record
+	var groupDiagnostics []*mes.Diagnostic
+	var groupDir string
+	flush := func() {
+		if len(group) > 0 {
+			emit(mes.MergeRankFiles(group), groupDiagnostics)
 		}
+		group, groupDiagnostics = nil, nil
+	}
 
-		// Search for footer information.
-		if end {
-			if moduleType == SMP && strings.HasPrefix(line, " Number of CPU's") {
-				record.NumCpus, _ = parseInt([]rune(line), 16, 21)
-				continue
-			}
-			if strings.HasPrefix(line, " N o r m a l    t e r m i n a t i o n") {
-				record.NormalTermination = true
-				continue
-			}
-			if strings.HasPrefix(line, " Elapsed time") {
-				// Use regexp because Elapsed time is not a fixed format.
-				r := regexp.MustCompile(`^ Elapsed time\s*(\d+)\s*seconds`)
-				results := r.FindStringSubmatch(line)
-				if len(results) == 2 {
-					seconds, _ := strconv.ParseFloat(results[1], 64)
-					record.ElapsedTime = seconds
+	for ch := range queue {
+		r := <-ch
+		<-semaphore
+		if opts.Out.MergeRanks && r.record != nil {
+			if _, ok := mes.RankFileNumber(r.record.File); ok {
+				dir := filepath.Dir(r.record.File)
+				if dir != groupDir {
+					flush()
+					groupDir = dir
 				}
+				group = append(group, r.record)
+				groupDiagnostics = append(groupDiagnostics, r.diagnostics...)
 				continue
 			}
 		}
+		flush()
+		groupDir = ""
+		emit(r.record, r.diagnostics)
 	}
-	return &record, nil
-}
-
-func parseName(runes []rune, start, end int) string {
-	str := string(runes[start:end])
-	return strings.TrimRight(strings.TrimRight(strings.Trim(str, " "), "."), " ")
+	flush()
 }
 
-func parseText(runes []rune, start, end int) string {
-	str := string(runes[start:end])
-	return strings.Trim(str, " ")
-}
+// ParseMessageFile parses LS-DYNA message file (e.g. messag, mes****), archive member or stdin,
+// decompressing it if needed, and return record.
+func (cli *CLI) ParseMessageFile(src *source) (*mes.Record, error) {
+	r, err := src.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
 
-func parseInt(runes []rune, start, end int) (int64, error) {
-	str := string(runes[start:end])
-	str = strings.Trim(str, " ")
-	return strconv.ParseInt(str, 10, 64)
+	return mes.ParseReader(r, src.Name, sourceOptions(src))
 }
 
-func parseFloat(runes []rune, start, end int) (float64, error) {
-	str := string(runes[start:end])
-	str = strings.Trim(str, " ")
-	return strconv.ParseFloat(str, 64)
+// sourceOptions returns options of how to store path of src in Record.File.
+func sourceOptions(src *source) *mes.Options {
+	// Stdin is not a path to be translated.
+	if src.Name == stdinName {
+		return nil
+	}
+	return &mes.Options{
+		Absolute: opts.Out.Abs,
+		Relative: opts.Out.Relative,
+	}
 }
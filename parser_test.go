@@ -0,0 +1,103 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lsti/mes"
+)
+
+// writeTestTar writes tar archive of members with content of file in mes/testdata.
+func writeTestTar(t *testing.T, members []string, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("mes", "testdata", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "runs.tar")
+	fp, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fp.Close()
+	tw := tar.NewWriter(fp)
+	for _, name := range members {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.ToSlash(archive)
+}
+
+func TestEachMessageFileMergeRanksInArchive(t *testing.T) {
+	// Rank files of run1 are not consecutive in the archive.
+	archive := writeTestTar(t, []string{"run1/mes0000", "run2/mes0000", "run1/mes0001"}, "r9_mpp_mes0000")
+	opts.Out.MergeRanks = true
+	defer func() { opts.Out.MergeRanks = false }()
+
+	cli := &CLI{errStream: new(bytes.Buffer)}
+	records, _ := cli.ParseMessageFiles([]string{archive})
+	want := []struct {
+		file  string
+		ranks int
+	}{
+		{archive + "!run1/mes0000", 2},
+		{archive + "!run2/mes0000", 1},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("len(records) = %d, want %d", len(records), len(want))
+	}
+	for i, w := range want {
+		if records[i].File != w.file || records[i].RankFiles == nil || len(records[i].RankFiles.Files) != w.ranks {
+			t.Errorf("records[%d] = %s of %+v, want %s of %d rank files", i, records[i].File, records[i].RankFiles, w.file, w.ranks)
+		}
+	}
+}
+
+func TestEachMessageFileJobs(t *testing.T) {
+	var files []string
+	for i := 0; i < 20; i++ {
+		files = append(files, fmt.Sprintf("run%d/messag", i))
+	}
+	defer func() { opts.Out.Jobs = 0 }()
+	for _, jobs := range []int{1, 3} {
+		opts.Out.Jobs = jobs
+		var inFlight, maxInFlight int32
+		cli := &CLI{errStream: new(bytes.Buffer), parse: func(src *source) (*mes.Record, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return &mes.Record{File: src.Name}, nil
+		}}
+		var got []string
+		cli.EachMessageFile(files, func(record *mes.Record, _ []*mes.Diagnostic) {
+			got = append(got, record.File)
+			// Slow callback lets workers get ahead if they are not bounded.
+			time.Sleep(time.Millisecond)
+		})
+		if maxInFlight > int32(jobs) {
+			t.Errorf("jobs %d: %d files parsed at once", jobs, maxInFlight)
+		}
+		if len(got) != len(files) || got[0] != files[0] || got[len(got)-1] != files[len(files)-1] {
+			t.Errorf("jobs %d: files = %v, want in order of %v", jobs, got, files)
+		}
+	}
+}
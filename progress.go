@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+
+	"lsti/mes"
+)
+
+// WriteProgress writes progress history of records to stdout.
+func (cli *CLI) WriteProgress(records []*mes.Record) error {
+	ds := cli.NormalizeProgress(records)
+	if len(ds) == 0 {
+		return errors.New("No status lines of the solution found")
+	}
+	return cli.WriteData(ds)
+}
+
+// NormalizeProgress normalizes progress history of records for json output.
+// Each status line becomes a record that has only properties. Wall time is measured
+// from the first status line, and rate is simulation time advanced per wall-clock second
+// since the previous status line printed at a different time.
+func (cli *CLI) NormalizeProgress(records []*mes.Record) []*RecordData {
+	naWord := opts.Out.Miss
+	var ds []*RecordData
+	for _, record := range records {
+		var first, previous *mes.Status
+		for _, status := range record.Progress {
+			var wallTime, rate interface{} = naWord, naWord
+			if !status.Date.IsZero() {
+				if first == nil {
+					first = status
+				}
+				wallTime = formatValue(status.Date.Sub(first.Date).Seconds(), ClockSec)
+				if previous == nil {
+					previous = status
+				} else if status.Date.After(previous.Date) {
+					rate = formatValue((status.Time-previous.Time)/status.Date.Sub(previous.Date).Seconds(), "")
+					previous = status
+				}
+			}
+			ds = append(ds, &RecordData{
+				Properties: []*JsonData{
+					{Name: "file", Value: record.File},
+					{Name: "cycle", Value: status.Cycle},
+					{Name: "time", Value: status.Time},
+					{Name: "timeStep", Value: status.TimeStep},
+					{Name: "wallTime", Value: wallTime},
+					{Name: "rate", Value: rate},
+					{Name: "event", Value: status.Event},
+				},
+				Timings: make([]*TimingData, 0),
+			})
+		}
+	}
+	return ds
+}
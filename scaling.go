@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"lsti/mes"
+)
+
+// FitAmdahl fits times measured with number of CPUs to Amdahl's law T(n) = a + b/n
+// by least squares, and returns serial fraction a/(a+b).
+// Points of no CPUs or times not finite are ignored.
+// It reports false if less than 2 distinct numbers of CPUs are given, or the fit is not finite.
+func FitAmdahl(cpus []int64, times []float64) (float64, bool) {
+	var xs, ys []float64
+	for i := range cpus {
+		if cpus[i] <= 0 || !isFinite(times[i]) {
+			continue
+		}
+		xs = append(xs, 1/float64(cpus[i]))
+		ys = append(ys, times[i])
+	}
+	if len(xs) < 2 {
+		return 0, false
+	}
+	n := float64(len(xs))
+	meanX, meanY := 0.0, 0.0
+	for i := range xs {
+		meanX += xs[i] / n
+		meanY += ys[i] / n
+	}
+	sxx, sxy := 0.0, 0.0
+	for i := range xs {
+		dx := xs[i] - meanX
+		sxx += dx * dx
+		sxy += dx * (ys[i] - meanY)
+	}
+	if sxx == 0 {
+		return 0, false
+	}
+	b := sxy / sxx
+	a := meanY - b*meanX
+	if a+b == 0 {
+		return 0, false
+	}
+	fraction := a / (a + b)
+	return fraction, isFinite(fraction)
+}
+
+// isFinite reports whether value is neither infinity nor NaN.
+func isFinite(value float64) bool {
+	return !math.IsInf(value, 0) && !math.IsNaN(value)
+}
+
+// A scalingPoint represents mean times of runs that have the same number of CPUs.
+type scalingPoint struct {
+	NumCpus int64
+	Runs    int
+	Elapsed float64
+	Means   map[string]float64
+}
+
+// WriteScaling writes scaling analysis of records to stdout.
+func (cli *CLI) WriteScaling(records []*mes.Record) error {
+	ds, err := cli.NormalizeScaling(records)
+	if err != nil {
+		return err
+	}
+	return cli.WriteData(ds)
+}
+
+// NormalizeScaling normalizes scaling analysis of records for json output.
+// Records are grouped by "-g, --group-by" option, and for each group and number of CPUs
+// mean time, speedup and parallel efficiency relative to the smallest number of CPUs become records.
+// Serial fraction fitted to Amdahl's law becomes a record for each group.
+// With "--weak", problem size grows with number of CPUs in each group, and only mean time
+// and efficiency (time of the smallest number of CPUs divided by time) become records.
+func (cli *CLI) NormalizeScaling(records []*mes.Record) ([]*RecordData, error) {
+	dataType, err := singleTarget("scaling")
+	if err != nil {
+		return nil, err
+	}
+	if !isSeconds(dataType) {
+		return nil, fmt.Errorf("Scaling analysis requires target %s or %s", CpuSec, ClockSec)
+	}
+	naWord := opts.Out.Miss
+	key := groupFields[opts.Scaling.GroupBy]
+
+	// Runs without number of CPUs (e.g. SMP file without the footer) cannot be compared.
+	var runs []*mes.Record
+	for _, record := range records {
+		if record.NumCpus <= 0 {
+			fmt.Fprintln(cli.errStream, &mes.Diagnostic{File: record.File, Severity: mes.Warning, Message: "number of CPUs not found, skipped in scaling analysis"})
+			continue
+		}
+		runs = append(runs, record)
+	}
+	if len(runs) == 0 {
+		return nil, errors.New("No runs with number of CPUs found")
+	}
+
+	var ds []*RecordData
+	for _, group := range groupRecords(runs, key) {
+		structure := collectSeries(group.Records, dataType)
+
+		// Average runs for each number of CPUs in ascending order.
+		var points []*scalingPoint
+		byCpus := groupRecords(group.Records, func(r *mes.Record) string {
+			return strconv.FormatInt(r.NumCpus, 10)
+		})
+		for _, g := range byCpus {
+			_, means := meanTimings(g.Records, dataType)
+			points = append(points, &scalingPoint{
+				NumCpus: g.Records[0].NumCpus,
+				Runs:    len(g.Records),
+				Elapsed: meanOf(g.Records, func(r *mes.Record) float64 { return r.ElapsedTime }),
+				Means:   means,
+			})
+		}
+		sort.Slice(points, func(i, j int) bool { return points[i].NumCpus < points[j].NumCpus })
+		base := points[0]
+
+		row := func(numCpus, runs interface{}, quantity string, elapsed interface{}, value func(string) interface{}) {
+			ds = append(ds, &RecordData{
+				Properties: []*JsonData{
+					{Name: "group", Value: group.Key},
+					{Name: "numCpus", Value: numCpus},
+					{Name: "runs", Value: runs},
+					{Name: "quantity", Value: quantity},
+					{Name: "elapsedTime", Value: elapsed},
+				},
+				Timings: scalingTimings(structure, value),
+			})
+		}
+
+		// ratio returns base / value of name, or naWord if not available.
+		ratio := func(p *scalingPoint, name string, scale float64) interface{} {
+			b, ok1 := base.Means[name]
+			v, ok2 := p.Means[name]
+			if !ok1 || !ok2 || v == 0 || !isFinite(b/v*scale) {
+				return naWord
+			}
+			return roundTo(b/v*scale, 4)
+		}
+		elapsedRatio := func(p *scalingPoint, scale float64) interface{} {
+			if p.Elapsed == 0 || !isFinite(base.Elapsed/p.Elapsed*scale) {
+				return naWord
+			}
+			return roundTo(base.Elapsed/p.Elapsed*scale, 4)
+		}
+
+		for _, p := range points {
+			p := p
+			row(p.NumCpus, p.Runs, "time", formatValue(p.Elapsed, ClockSec), func(name string) interface{} {
+				if v, ok := p.Means[name]; ok {
+					return formatValue(v, dataType)
+				}
+				return naWord
+			})
+			// Efficiency of weak scaling is the ratio of times, since the work per CPU is the same.
+			if opts.Scaling.Weak {
+				row(p.NumCpus, p.Runs, "efficiency", elapsedRatio(p, 1), func(name string) interface{} {
+					return ratio(p, name, 1)
+				})
+				continue
+			}
+			// Efficiency is speedup relative to the increase of CPUs.
+			efficiency := float64(base.NumCpus) / float64(p.NumCpus)
+			row(p.NumCpus, p.Runs, "speedup", elapsedRatio(p, 1), func(name string) interface{} {
+				return ratio(p, name, 1)
+			})
+			row(p.NumCpus, p.Runs, "efficiency", elapsedRatio(p, efficiency), func(name string) interface{} {
+				return ratio(p, name, efficiency)
+			})
+		}
+
+		// Amdahl's law is for a fixed problem size.
+		if opts.Scaling.Weak {
+			continue
+		}
+
+		// serialFraction fits values of points to Amdahl's law.
+		serialFraction := func(value func(*scalingPoint) (float64, bool)) interface{} {
+			var cpus []int64
+			var times []float64
+			for _, p := range points {
+				if v, ok := value(p); ok {
+					cpus = append(cpus, p.NumCpus)
+					times = append(times, v)
+				}
+			}
+			if s, ok := FitAmdahl(cpus, times); ok {
+				return roundTo(s, 4)
+			}
+			return naWord
+		}
+		elapsed := serialFraction(func(p *scalingPoint) (float64, bool) { return p.Elapsed, true })
+		row(naWord, len(group.Records), "serialFraction", elapsed, func(name string) interface{} {
+			return serialFraction(func(p *scalingPoint) (float64, bool) {
+				v, ok := p.Means[name]
+				return v, ok
+			})
+		})
+	}
+	return ds, nil
+}
+
+// scalingTimings returns timings that have value of each timing row in structure.
+func scalingTimings(structure []*series, value func(name string) interface{}) []*TimingData {
+	timings := make([]*TimingData, 0)
+	for _, p := range structure {
+		timing := TimingData{}
+		timing.Name = p.Name
+		timing.Value = value(timingName(p.Name, ""))
+		timing.Details = make([]*JsonData, 0)
+		if !opts.Out.Simple {
+			for _, c := range p.Children {
+				timing.Details = append(timing.Details, &JsonData{Name: c.Name, Value: value(timingName(p.Name, c.Name))})
+			}
+		}
+		timings = append(timings, &timing)
+	}
+	return timings
+}
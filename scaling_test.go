@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"lsti/mes"
+)
+
+func TestFitAmdahl(t *testing.T) {
+	// T(n) = 10 + 90/n has serial fraction 0.1.
+	amdahl := func(n int64) float64 { return 10 + 90/float64(n) }
+	tests := []struct {
+		name  string
+		cpus  []int64
+		times []float64
+		want  float64
+		ok    bool
+	}{
+		{"fit", []int64{1, 2, 4, 8}, []float64{amdahl(1), amdahl(2), amdahl(4), amdahl(8)}, 0.1, true},
+		{"no CPUs ignored", []int64{0, 2, 4}, []float64{amdahl(1), amdahl(2), amdahl(4)}, 0.1, true},
+		{"not finite ignored", []int64{1, 2, 4}, []float64{math.NaN(), amdahl(2), amdahl(4)}, 0.1, true},
+		{"single point", []int64{0, 4}, []float64{100, amdahl(4)}, 0, false},
+		{"same CPUs", []int64{4, 4}, []float64{30, 32}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := FitAmdahl(tt.cpus, tt.times)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: FitAmdahl() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeScalingWeak(t *testing.T) {
+	opts.Out.Target = ClockSec
+	opts.Out.Duration = Seconds
+	opts.Scaling.GroupBy = "platform"
+	opts.Scaling.Weak = true
+	defer func() { opts.Scaling.Weak = false }()
+
+	// Input files grow with number of CPUs on the same platform.
+	var records []*mes.Record
+	for _, run := range []struct {
+		file, input string
+		numCpus     int64
+		elapsed     float64
+	}{
+		{"study/small/messag", "small.k", 1, 100},
+		{"study/medium/messag", "medium.k", 2, 110},
+		{"study/large/messag", "large.k", 4, 125},
+	} {
+		record := &mes.Record{File: run.file, InputFile: run.input, NumCpus: run.numCpus, ElapsedTime: run.elapsed}
+		record.AddParent("Element processing", run.elapsed, 100, run.elapsed, 100)
+		records = append(records, record)
+	}
+
+	ds, err := (&CLI{errStream: new(bytes.Buffer)}).NormalizeScaling(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		quantity string
+		elapsed  interface{}
+	}{
+		{"time", 100.0}, {"efficiency", 1.0},
+		{"time", 110.0}, {"efficiency", 0.9091},
+		{"time", 125.0}, {"efficiency", 0.8},
+	}
+	if len(ds) != len(want) {
+		t.Fatalf("len(rows) = %d, want %d without speedup and serial fraction", len(ds), len(want))
+	}
+	for i, w := range want {
+		var quantity, elapsed interface{}
+		for _, p := range ds[i].Properties {
+			switch p.Name {
+			case "quantity":
+				quantity = p.Value
+			case "elapsedTime":
+				elapsed = p.Value
+			}
+		}
+		if quantity != w.quantity || elapsed != w.elapsed {
+			t.Errorf("rows[%d] = %v %v, want %s %v", i, quantity, elapsed, w.quantity, w.elapsed)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/mattn/go-zglob"
+	"github.com/ulikunitz/xz"
+)
+
+// archiveSeparator separates archive path and member path (e.g. runs.tar.gz!run1/mes0000).
+const archiveSeparator = "!"
+
+// stdinName is the file path to read stdin.
+const stdinName = "-"
+
+// decompressors open decompressing readers by file extension.
+var decompressors = map[string]func(io.Reader) (io.ReadCloser, error){
+	".gz": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	".bz2": func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bzip2.NewReader(r)), nil
+	},
+	".xz": func(r io.Reader) (io.ReadCloser, error) {
+		zr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(zr), nil
+	},
+	".zst": func(r io.Reader) (io.ReadCloser, error) {
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	},
+}
+
+// messageNamePattern matches base names of message files and d3hsp file in archives.
+var messageNamePattern = regexp.MustCompile(`^(messag|d3hsp|mes\d+)$`)
+
+// A source is a file or an archive member to be parsed.
+type source struct {
+	// Name is the file path, or archive path and member path joined by archiveSeparator.
+	Name string
+
+	// Open opens decompressed content.
+	Open func() (io.ReadCloser, error)
+}
+
+// trimCompression returns name without compression extension (e.g. ".gz"), and the extension.
+func trimCompression(name string) (string, string) {
+	ext := strings.ToLower(path.Ext(name))
+	if _, ok := decompressors[ext]; ok {
+		return name[:len(name)-len(ext)], ext
+	}
+	return name, ""
+}
+
+// isArchive reports whether file is a zip archive or tar archive (optionally compressed).
+func isArchive(name string) bool {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".zip") {
+		return true
+	}
+	base, _ := trimCompression(lower)
+	return strings.HasSuffix(base, ".tar")
+}
+
+// isMessageName reports whether member of archive looks like a message file or d3hsp file.
+func isMessageName(name string) bool {
+	base, _ := trimCompression(path.Base(name))
+	return messageNamePattern.MatchString(base)
+}
+
+// splitArchivePath splits pattern like "runs.tar.gz!run1/mes*" into archive and member pattern.
+// It reports false if pattern does not refer to archive members.
+func splitArchivePath(pattern string) (string, string, bool) {
+	i := strings.Index(pattern, archiveSeparator)
+	if i < 0 || !isArchive(pattern[:i]) {
+		return pattern, "", false
+	}
+	return pattern[:i], pattern[i+len(archiveSeparator):], true
+}
+
+// decompress wraps r with decompressing reader for compression extension of name.
+func decompress(r io.ReadCloser, name string) (io.ReadCloser, error) {
+	_, ext := trimCompression(name)
+	if strings.HasSuffix(strings.ToLower(name), ".tgz") {
+		ext = ".gz"
+	}
+	open, ok := decompressors[ext]
+	if !ok {
+		return r, nil
+	}
+	dr, err := open(r)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return &multiCloser{ReadCloser: dr, next: r}, nil
+}
+
+// A multiCloser closes decompressing reader and underlying reader.
+type multiCloser struct {
+	io.ReadCloser
+	next io.Closer
+}
+
+// Close closes both readers.
+func (c *multiCloser) Close() error {
+	err := c.ReadCloser.Close()
+	if err2 := c.next.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// openFile opens file decompressing by its extension (.gz, .bz2, .xz or .zst).
+func openFile(name string) (io.ReadCloser, error) {
+	fp, err := os.Open(filepath.FromSlash(name))
+	if err != nil {
+		return nil, err
+	}
+	return decompress(fp, name)
+}
+
+// isPiped reports whether r is a pipe or redirected file, not a terminal.
+func isPiped(r io.Reader) bool {
+	fp, ok := r.(*os.File)
+	if !ok {
+		return r != nil
+	}
+	info, err := fp.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// eachArchiveMember executes callback function for each regular file in archive
+// whose path matches glob pattern, or looks like a message file if pattern is empty.
+// Members are read into memory so that they can be parsed after the archive is closed,
+// and passed in order of their paths as files are sorted (e.g. rank files of a run are consecutive).
+func eachArchiveMember(archive, pattern string, cb func(*source)) error {
+	var sources []*source
+	member := func(name string, data []byte) {
+		sources = append(sources, &source{
+			Name: archive + archiveSeparator + name,
+			Open: func() (io.ReadCloser, error) {
+				return decompress(ioutil.NopCloser(bytes.NewReader(data)), name)
+			},
+		})
+	}
+	err := readArchiveMembers(archive, pattern, member)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	for _, src := range sources {
+		cb(src)
+	}
+	return err
+}
+
+// readArchiveMembers reads content of each member of archive that matches pattern (see eachArchiveMember)
+// in archive order.
+func readArchiveMembers(archive, pattern string, member func(name string, data []byte)) error {
+	matches := func(name string) bool {
+		if pattern == "" {
+			return isMessageName(name)
+		}
+		matched, _ := zglob.Match(pattern, name)
+		return matched
+	}
+
+	if strings.HasSuffix(strings.ToLower(archive), ".zip") {
+		zr, err := zip.OpenReader(filepath.FromSlash(archive))
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() || !matches(f.Name) {
+				continue
+			}
+			fp, err := f.Open()
+			if err != nil {
+				return err
+			}
+			data, err := ioutil.ReadAll(fp)
+			fp.Close()
+			if err != nil {
+				return err
+			}
+			member(f.Name, data)
+		}
+		return nil
+	}
+
+	fp, err := openFile(archive)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	tr := tar.NewReader(fp)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || !matches(header.Name) {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		member(header.Name, data)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"lsti/mes"
+)
+
+// Statistics represents summary statistics of a series of values.
+type Statistics struct {
+	Count                       int
+	Min, Max, Mean, Median, Std float64
+	Percentiles                 []float64
+}
+
+// ComputeStatistics computes statistics of values.
+// percentiles are given in the range of 0 to 100, and computed by linear interpolation.
+// Std is the sample standard deviation (0 for less than 2 values).
+func ComputeStatistics(values []float64, percentiles []float64) *Statistics {
+	stats := Statistics{Count: len(values)}
+	if len(values) == 0 {
+		stats.Percentiles = make([]float64, len(percentiles))
+		return &stats
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	stats.Mean = sum / float64(len(sorted))
+
+	if len(sorted) > 1 {
+		sq := 0.0
+		for _, v := range sorted {
+			sq += (v - stats.Mean) * (v - stats.Mean)
+		}
+		stats.Std = math.Sqrt(sq / float64(len(sorted)-1))
+	}
+
+	stats.Median = percentile(sorted, 50)
+	for _, p := range percentiles {
+		stats.Percentiles = append(stats.Percentiles, percentile(sorted, p))
+	}
+	return &stats
+}
+
+// percentile returns p-th percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	pos := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// A series represents values of a timing row collected across records.
+type series struct {
+	Name     string
+	Values   []float64
+	Children []*series
+}
+
+// child returns child series with the given name, adding it if not found.
+func (s *series) child(name string) *series {
+	for _, c := range s.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &series{Name: name}
+	s.Children = append(s.Children, c)
+	return c
+}
+
+// collectSeries collects values of dataType for each parent and child in order of appearance.
+// Derived metrics not available in a record are not collected.
+func collectSeries(records []*mes.Record, dataType string) []*series {
+	root := &series{}
+	for _, record := range records {
+		add := func(s *series, data *mes.Data) {
+			if v, ok := record.GetMetric(data, dataType); ok {
+				s.Values = append(s.Values, v)
+			}
+		}
+		record.ForEachParent(func(parent *mes.Parent, _ int) {
+			p := root.child(parent.Name)
+			add(p, &parent.Data)
+			parent.ForEachChildren(func(child *mes.Child, _ int) {
+				c := p.child(child.Name)
+				add(c, &child.Data)
+			})
+		})
+	}
+	return root.Children
+}
+
+// WriteStats writes statistics of timings across records to stdout.
+func (cli *CLI) WriteStats(records []*mes.Record) error {
+	ds, err := cli.NormalizeStats(records)
+	if err != nil {
+		return err
+	}
+	return cli.WriteData(ds)
+}
+
+// NormalizeStats normalizes statistics of timings across records for json output.
+// Each statistic (e.g. mean) becomes a record that has "statistic" property.
+func (cli *CLI) NormalizeStats(records []*mes.Record) ([]*RecordData, error) {
+	dataType, err := singleTarget("stats")
+	if err != nil {
+		return nil, err
+	}
+	percentiles := opts.Stats.Percentiles
+	for _, p := range percentiles {
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("Invalid percentile: %v (must be between 0 and 100)", p)
+		}
+	}
+
+	names := []string{"count", "min", "max", "mean", "median", "std"}
+	for _, p := range percentiles {
+		names = append(names, "p"+strconv.FormatFloat(p, 'f', -1, 64))
+	}
+
+	// values returns statistics of s in the same order as names.
+	// Statistics of no values (e.g. derived metric not available) are missing.
+	values := func(s *series) []interface{} {
+		stats := ComputeStatistics(s.Values, percentiles)
+		if stats.Count == 0 {
+			vs := []interface{}{0}
+			for i := 1; i < len(names); i++ {
+				vs = append(vs, opts.Out.Miss)
+			}
+			return vs
+		}
+		vs := []interface{}{
+			stats.Count,
+			formatValue(stats.Min, dataType),
+			formatValue(stats.Max, dataType),
+			formatValue(stats.Mean, dataType),
+			formatValue(stats.Median, dataType),
+			formatValue(stats.Std, dataType),
+		}
+		for _, p := range stats.Percentiles {
+			vs = append(vs, formatValue(p, dataType))
+		}
+		return vs
+	}
+
+	ds := make([]*RecordData, len(names))
+	for i, name := range names {
+		ds[i] = &RecordData{
+			Properties: []*JsonData{{Name: "statistic", Value: name}},
+			Timings:    make([]*TimingData, 0),
+		}
+	}
+	for _, p := range collectSeries(records, dataType) {
+		pvs := values(p)
+		timings := make([]*TimingData, len(names))
+		for i := range names {
+			timings[i] = &TimingData{JsonData: JsonData{Name: p.Name, Value: pvs[i]}, Details: make([]*JsonData, 0)}
+			ds[i].Timings = append(ds[i].Timings, timings[i])
+		}
+		if opts.Out.Simple {
+			continue
+		}
+		for _, c := range p.Children {
+			cvs := values(c)
+			for i := range names {
+				timings[i].Details = append(timings[i].Details, &JsonData{Name: c.Name, Value: cvs[i]})
+			}
+		}
+	}
+	return ds, nil
+}
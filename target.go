@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// AllTargets is the value of "-t, --target" option that selects all targets.
+const AllTargets = "all"
+
+// allTargets are targets selected by "all" in order of Data fields.
+var allTargets = []string{CpuSec, CpuPercent, ClockSec, ClockPercent}
+
+// derivedTargets are targets of metrics derived from timings and record,
+// which are selected only by name.
+var derivedTargets = []string{CpuRatio, CoreUtilization, ElementCycle}
+
+// isDerived reports whether dataType is a derived metric.
+func isDerived(dataType string) bool {
+	for _, t := range derivedTargets {
+		if t == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// isHigherBetter reports whether higher value of dataType is better (e.g. more CPU time per wall-clock time),
+// while lower is better for timings.
+func isHigherBetter(dataType string) bool {
+	return dataType == CpuRatio || dataType == CoreUtilization
+}
+
+// parseTargets parses value of "-t, --target" option, a target or comma-separated list of targets
+// (e.g. cpusec,clocksec), or "all". Duplicated targets are removed.
+func parseTargets(value string) ([]string, error) {
+	var ts []string
+	add := func(target string) {
+		for _, t := range ts {
+			if t == target {
+				return
+			}
+		}
+		ts = append(ts, target)
+	}
+	for _, target := range strings.Split(value, ",") {
+		target = strings.TrimSpace(target)
+		if target == AllTargets {
+			for _, t := range allTargets {
+				add(t)
+			}
+			continue
+		}
+		valid := isDerived(target)
+		for _, t := range allTargets {
+			valid = valid || t == target
+		}
+		if !valid {
+			choices := append(append([]string{}, allTargets...), derivedTargets...)
+			return nil, fmt.Errorf("Invalid target: %q (must be %s or %s)", target, strings.Join(choices, ", "), AllTargets)
+		}
+		add(target)
+	}
+	return ts, nil
+}
+
+// targets returns targets of "-t, --target" option, which is validated in Run.
+func targets() []string {
+	ts, _ := parseTargets(opts.Out.Target)
+	return ts
+}
+
+// singleTarget returns target of "-t, --target" option for command that uses only one target,
+// or error if multiple targets are specified.
+func singleTarget(command string) (string, error) {
+	ts := targets()
+	if len(ts) != 1 {
+		return "", fmt.Errorf("\"%s\" command requires a single target, but %d targets are specified", command, len(ts))
+	}
+	return ts[0], nil
+}
+
+// MetricValues are values of a timing for multiple targets.
+// They are marshaled to JSON object in order of targets (e.g. {"cpusec": 1.2, "clocksec": 1.4}).
+type MetricValues []*JsonData
+
+// MarshalJSON marshals values to JSON object keeping order of targets.
+func (values MetricValues) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(v.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// metricValues returns values of targets if value is MetricValues
+// or JSON object unmarshaled from them, otherwise it reports false.
+func metricValues(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case MetricValues:
+		m := make(map[string]interface{}, len(v))
+		for _, d := range v {
+			m[d.Name] = d.Value
+		}
+		return m, true
+	case map[string]interface{}:
+		return v, true
+	}
+	return nil, false
+}
+
+// formatMetricValues formats values of targets in a line (e.g. "cpusec 0:00:01, clocksec 0:00:01").
+// Value of single target is formatted as is.
+func formatMetricValues(value interface{}) string {
+	m, ok := metricValues(value)
+	if !ok {
+		return formatCell(value)
+	}
+	var ss []string
+	for _, target := range targets() {
+		if v, ok := m[target]; ok {
+			ss = append(ss, target+" "+formatCell(v))
+		}
+	}
+	return strings.Join(ss, ", ")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"lsti/mes"
+)
+
+// includesTermination reports whether termination class of record is specified
+// by "--termination" option, or the option is not specified.
+func includesTermination(record *mes.Record) bool {
+	if len(opts.Out.Terminations) == 0 {
+		return true
+	}
+	for _, class := range opts.Out.Terminations {
+		if record.Termination.Class == class {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTermination writes termination of records to stdout.
+func (cli *CLI) WriteTermination(records []*mes.Record) error {
+	return cli.WriteData(cli.NormalizeTermination(records))
+}
+
+// NormalizeTermination normalizes termination of records for json output.
+// If "-c, --count" option is specified, each termination class becomes a record
+// with the number of runs, otherwise each run becomes a record.
+func (cli *CLI) NormalizeTermination(records []*mes.Record) []*RecordData {
+	var ds []*RecordData
+	if opts.Termination.Count {
+		counts := make(map[string]int)
+		for _, record := range records {
+			counts[record.Termination.Class]++
+		}
+		for _, class := range mes.TerminationClasses {
+			ds = append(ds, &RecordData{
+				Properties: []*JsonData{
+					{Name: "termination", Value: class},
+					{Name: "runs", Value: counts[class]},
+				},
+				Timings: make([]*TimingData, 0),
+			})
+		}
+		return ds
+	}
+
+	for _, record := range records {
+		t := record.Termination
+		ds = append(ds, &RecordData{
+			Properties: []*JsonData{
+				{Name: "file", Value: record.File},
+				{Name: "termination", Value: t.Class},
+				{Name: "cycle", Value: t.Cycle},
+				{Name: "time", Value: t.Time},
+				{Name: "line", Value: t.Line},
+				{Name: "message", Value: t.Message},
+			},
+			Timings: make([]*TimingData, 0),
+		})
+	}
+	return ds
+}
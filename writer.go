@@ -4,19 +4,27 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jmespath/go-jmespath"
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/russross/blackfriday.v2"
+
+	"lsti/mes"
 )
 
 // Write results to stdout.
-func (cli *CLI) Write(records []*Record) error {
-	ds := cli.NormalizeRecords(records)
+func (cli *CLI) Write(records []*mes.Record) error {
+	return cli.WriteData(cli.NormalizeRecords(records))
+}
 
+// WriteData writes normalized data to stdout.
+func (cli *CLI) WriteData(ds []*RecordData) error {
 	data, err := json.MarshalIndent(ds, "", "  ")
 	if err != nil {
 		return err
@@ -33,29 +41,31 @@ func (cli *CLI) Write(records []*Record) error {
 
 	// Format result string to specified format.
 	str := ""
-	f := opts.Out.Output
-	if f == "" {
-		if len(records) == 1 {
-			// Simple is default for single file.
-			f = Simple
-		} else {
-			// Table is default for multiple files.
-			f = Table
-		}
-	}
-	switch f {
+	switch outputFormat(len(ds)) {
 	case Csv:
 		str = cli.FormatSeparatedValues(data, ',', true)
 	case Html:
 		str = cli.FormatHtml(data)
 	case Json:
 		str = string(data) + "\n"
+	case Jsonl:
+		str, err = cli.FormatJsonLines(data)
+		if err != nil {
+			return err
+		}
+	case Parquet:
+		return errors.New("Parquet output requires \"-l, --long\" option without command")
 	case Simple:
 		str = cli.FormatSimple(data)
 	case Table:
 		str = cli.FormatTable(data)
 	case Tsv:
 		str = cli.FormatSeparatedValues(data, '	', false)
+	case Xlsx:
+		str, err = cli.FormatXlsx(data)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Write to stdout.
@@ -63,6 +73,160 @@ func (cli *CLI) Write(records []*Record) error {
 	return nil
 }
 
+// FormatJsonLines formats json data to JSON Lines, an element of array in each line.
+func (cli *CLI) FormatJsonLines(data []byte) (string, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		// Result of query may not be an array.
+		elements = []json.RawMessage{data}
+	}
+	buf := new(bytes.Buffer)
+	for _, element := range elements {
+		if err := json.Compact(buf, element); err != nil {
+			return "", err
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String(), nil
+}
+
+// outputFormat returns "-o, --output" format, or default format for n records.
+func outputFormat(n int) string {
+	if opts.Out.Output != "" {
+		return opts.Out.Output
+	}
+	if n == 1 {
+		// Simple is default for single file.
+		return Simple
+	}
+	// Table is default for multiple files.
+	return Table
+}
+
+// WriteFiles parses files and writes results to stdout.
+// Simple and json formats without "-q, --query" are written as each file is parsed,
+// and other formats are written after all files are parsed.
+// In both cases, records are discarded once normalized.
+func (cli *CLI) WriteFiles(files []string) error {
+	if opts.Out.Long {
+		return cli.writeLongFiles(files)
+	}
+
+	// An archive may have multiple files, so it is formatted as multiple records by default.
+	n := len(files)
+	if archive, _, _ := splitArchivePath(files[0]); n == 1 && isArchive(archive) {
+		n = 2
+	}
+	format := outputFormat(n)
+	streaming := opts.Out.Query == "" && !opts.Out.Strict && (format == Simple || format == Json)
+
+	// Empty results are an empty array, not null.
+	ds := make([]*RecordData, 0)
+	count := 0
+	problems := 0
+	var err error
+	cli.EachMessageFile(files, func(record *mes.Record, diagnostics []*mes.Diagnostic) {
+		for _, d := range diagnostics {
+			fmt.Fprintln(cli.errStream, d)
+		}
+		problems += len(diagnostics)
+		if record == nil || err != nil {
+			return
+		}
+
+		d := cli.NormalizeRecord(record)
+		if !streaming {
+			ds = append(ds, d)
+			return
+		}
+		err = cli.writeRecord(d, format, count)
+		count++
+	})
+	if err != nil {
+		return err
+	}
+
+	// If "--strict" is specified, any diagnostics are errors.
+	if opts.Out.Strict && problems > 0 {
+		return fmt.Errorf("%d problem(s) found while parsing files", problems)
+	}
+
+	if !streaming {
+		return cli.WriteData(ds)
+	}
+	if format == Json {
+		if count == 0 {
+			fmt.Fprintln(cli.outStream, "[]")
+		} else {
+			fmt.Fprint(cli.outStream, "\n]\n")
+		}
+	}
+	return nil
+}
+
+// writeLongFiles parses files and writes timings in long format as files are parsed.
+// If "--strict" is specified, nothing is written if any problem is found.
+func (cli *CLI) writeLongFiles(files []string) error {
+	w, err := cli.newLongWriter()
+	if err != nil {
+		return err
+	}
+	var ls []*LongData
+	problems := 0
+	cli.EachMessageFile(files, func(record *mes.Record, diagnostics []*mes.Diagnostic) {
+		for _, d := range diagnostics {
+			fmt.Fprintln(cli.errStream, d)
+		}
+		problems += len(diagnostics)
+		if record == nil || err != nil {
+			return
+		}
+		if opts.Out.Strict {
+			ls = append(ls, cli.NormalizeLong(record)...)
+			return
+		}
+		err = w.Write(cli.NormalizeLong(record))
+	})
+	if err != nil {
+		return err
+	}
+	if opts.Out.Strict {
+		if problems > 0 {
+			return fmt.Errorf("%d problem(s) found while parsing files", problems)
+		}
+		if err := w.Write(ls); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// writeRecord writes i-th normalized record to stdout in simple or json format.
+func (cli *CLI) writeRecord(d *RecordData, format string, i int) error {
+	data, err := json.MarshalIndent(d, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	if format == Json {
+		if i == 0 {
+			fmt.Fprint(cli.outStream, "[\n  ")
+		} else {
+			fmt.Fprint(cli.outStream, ",\n  ")
+		}
+		cli.outStream.Write(data)
+		return nil
+	}
+
+	var record RecordData
+	json.Unmarshal(data, &record)
+	if i != 0 {
+		fmt.Fprint(cli.outStream, "\n")
+	}
+	fmt.Fprint(cli.outStream, formatSimpleRecord(&record))
+	return nil
+}
+
 // Query applies JMESPath to json.
 func (cli *CLI) Query(data []byte, expression string) ([]byte, error) {
 	var d interface{}
@@ -86,8 +250,16 @@ func (cli *CLI) Query(data []byte, expression string) ([]byte, error) {
 
 // A RecordData represents record for json MarshalIndent.
 type RecordData struct {
-	Properties []*JsonData   `json:"properties"`
-	Timings    []*TimingData `json:"details"`
+	Properties  []*JsonData       `json:"properties"`
+	Timings     []*TimingData     `json:"details"`
+	Diagnostics []*DiagnosticData `json:"diagnostics,omitempty"`
+}
+
+// A DiagnosticData represents a problem found while parsing a file for json MarshalIndent.
+type DiagnosticData struct {
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
 }
 
 // A TimingData represents timing information struct that has parent-child relationship for json MarshalIndent.
@@ -103,80 +275,174 @@ type JsonData struct {
 }
 
 // NormalizeRecords normalizes records for json output.
-func (cli *CLI) NormalizeRecords(records []*Record) []interface{} {
-	dataType := opts.Out.Target
-	var jsonSet []interface{}
-	verbosity := len(opts.Out.Verbose)
+func (cli *CLI) NormalizeRecords(records []*mes.Record) []*RecordData {
+	var jsonSet []*RecordData
 	for _, record := range records {
-		var jsonOut RecordData
-
-		// Set properties.
-		properties := make([]*JsonData, 0)
-		properties = append(properties, &JsonData{Name: "file", Value: record.File})
-		if verbosity >= 1 {
-			if opts.Out.Duration == Human {
-				properties = append(properties, &JsonData{Name: "elapsedTime", Value: formatSeconds(record.ElapsedTime)})
-			} else {
-				properties = append(properties, &JsonData{Name: "elapsedTime", Value: record.ElapsedTime})
+		jsonSet = append(jsonSet, cli.NormalizeRecord(record))
+	}
+	return jsonSet
+}
+
+// NormalizeRecord normalizes a record for json output.
+func (cli *CLI) NormalizeRecord(record *mes.Record) *RecordData {
+	ts := targets()
+	verbosity := len(opts.Out.Verbose)
+	var jsonOut RecordData
+
+	// Set properties.
+	properties := make([]*JsonData, 0)
+	properties = append(properties, &JsonData{Name: "file", Value: record.File})
+	if record.Segment > 0 {
+		properties = append(properties, &JsonData{Name: "segment", Value: record.Segment})
+	}
+	if files := record.RankFiles; files != nil {
+		missing := make([]string, len(files.Missing))
+		for i, rank := range files.Missing {
+			missing[i] = strconv.FormatInt(rank, 10)
+		}
+		properties = append(properties, &JsonData{Name: "rankFiles", Value: len(files.Files)})
+		properties = append(properties, &JsonData{Name: "missingRanks", Value: strings.Join(missing, " ")})
+		// Imbalance is of the first target in seconds.
+		imbalanceType := ClockSec
+		for _, t := range ts {
+			if isSeconds(t) {
+				imbalanceType = t
+				break
 			}
-			properties = append(properties, &JsonData{Name: "version", Value: record.Version})
-			properties = append(properties, &JsonData{Name: "svnVersion", Value: record.SvnVersion})
-			properties = append(properties, &JsonData{Name: "platform", Value: record.Platform})
-			properties = append(properties, &JsonData{Name: "compiler", Value: record.Compiler})
-		}
-		if verbosity >= 2 {
-			properties = append(properties, &JsonData{Name: "NumCpus", Value: record.NumCpus})
-			properties = append(properties, &JsonData{Name: "os", Value: record.Os})
-			properties = append(properties, &JsonData{Name: "inputFile", Value: record.InputFile})
-			properties = append(properties, &JsonData{Name: "hostname", Value: record.Hostname})
-		}
-		if verbosity >= 3 {
-			properties = append(properties, &JsonData{Name: "revision", Value: record.Revision})
-			properties = append(properties, &JsonData{Name: "precision", Value: record.Precision})
-			properties = append(properties, &JsonData{Name: "licensedTo", Value: record.LicensedTo})
-			properties = append(properties, &JsonData{Name: "issuedBy", Value: record.IssuedBy})
-			properties = append(properties, &JsonData{Name: "normalTermination", Value: record.NormalTermination})
-		}
-		jsonOut.Properties = properties
-
-		// Set timings.
-		timings := make([]*TimingData, 0)
-		var pt *TimingData
-		record.ForEachData(func(d interface{}, _ int) {
-			if p, ok := d.(*Parent); ok {
-				timing := TimingData{}
-				timing.Name = p.Name
-				value := p.GetValue(dataType)
-				if opts.Out.Duration == Human && (dataType == CpuSec || dataType == ClockSec) {
-					timing.Value = formatSeconds(value)
-				} else {
-					timing.Value = value
-				}
-				timing.Details = make([]*JsonData, 0)
-				pt = &timing
-				timings = append(timings, &timing)
+		}
+		if imbalance := files.GetImbalance(imbalanceType); imbalance != nil {
+			properties = append(properties, &JsonData{Name: "maxMean", Value: roundTo(imbalance.MaxMean, 4)})
+			properties = append(properties, &JsonData{Name: "slowestRank", Value: imbalance.Slowest})
+		}
+	}
+	if verbosity >= 1 {
+		if opts.Out.Duration == Human {
+			properties = append(properties, &JsonData{Name: "elapsedTime", Value: formatSeconds(record.ElapsedTime)})
+		} else {
+			properties = append(properties, &JsonData{Name: "elapsedTime", Value: record.ElapsedTime})
+		}
+		properties = append(properties, &JsonData{Name: "version", Value: record.Version})
+		properties = append(properties, &JsonData{Name: "svnVersion", Value: record.SvnVersion})
+		properties = append(properties, &JsonData{Name: "platform", Value: record.Platform})
+		properties = append(properties, &JsonData{Name: "compiler", Value: record.Compiler})
+	}
+	if verbosity >= 2 {
+		properties = append(properties, &JsonData{Name: "NumCpus", Value: record.NumCpus})
+		properties = append(properties, &JsonData{Name: "os", Value: record.Os})
+		properties = append(properties, &JsonData{Name: "inputFile", Value: record.InputFile})
+		properties = append(properties, &JsonData{Name: "hostname", Value: record.Hostname})
+		properties = append(properties, &JsonData{Name: "fileType", Value: record.FileType})
+		properties = append(properties, &JsonData{Name: "memoryRequested", Value: record.Memory.Requested})
+		properties = append(properties, &JsonData{Name: "memoryRequired", Value: record.Memory.Required})
+		if record.FileType == mes.D3hspFile {
+			properties = append(properties, &JsonData{Name: "numNodes", Value: record.Model.NumNodes})
+			properties = append(properties, &JsonData{Name: "numParts", Value: record.Model.NumParts})
+			properties = append(properties, &JsonData{Name: "numSolids", Value: record.Model.NumSolids})
+			properties = append(properties, &JsonData{Name: "numShells", Value: record.Model.NumShells})
+			properties = append(properties, &JsonData{Name: "numThickShells", Value: record.Model.NumThickShells})
+			properties = append(properties, &JsonData{Name: "numBeams", Value: record.Model.NumBeams})
+		}
+	}
+	if verbosity >= 3 {
+		properties = append(properties, &JsonData{Name: "revision", Value: record.Revision})
+		properties = append(properties, &JsonData{Name: "precision", Value: record.Precision})
+		properties = append(properties, &JsonData{Name: "licensedTo", Value: record.LicensedTo})
+		properties = append(properties, &JsonData{Name: "issuedBy", Value: record.IssuedBy})
+		properties = append(properties, &JsonData{Name: "normalTermination", Value: record.NormalTermination})
+		properties = append(properties, &JsonData{Name: "termination", Value: record.Termination.Class})
+		properties = append(properties, &JsonData{Name: "numWarnings", Value: record.GetNumMessages(mes.Warning)})
+		properties = append(properties, &JsonData{Name: "numErrors", Value: record.GetNumMessages(mes.Error)})
+		properties = append(properties, &JsonData{Name: "memoryRequested2", Value: record.Memory.Requested2})
+		properties = append(properties, &JsonData{Name: "memoryRequired2", Value: record.Memory.Required2})
+		properties = append(properties, &JsonData{Name: "memoryTotal", Value: record.Memory.Total})
+		properties = append(properties, &JsonData{Name: "memoryExpansions", Value: record.Memory.Expansions})
+		if record.FileType == mes.D3hspFile {
+			properties = append(properties, &JsonData{Name: "terminationTime", Value: record.Control.TerminationTime})
+			properties = append(properties, &JsonData{Name: "timeStepScale", Value: record.Control.TimeStepScale})
+			properties = append(properties, &JsonData{Name: "massScaledTimeStep", Value: record.Control.MassScaledTimeStep})
+			properties = append(properties, &JsonData{Name: "addedMass", Value: record.MassScaling.AddedMass})
+			properties = append(properties, &JsonData{Name: "addedMassRatio", Value: record.MassScaling.Ratio})
+		}
+	}
+	jsonOut.Properties = properties
+
+	// Set timings.
+	// Value is of the target, or MetricValues of targets if multiple targets are specified.
+	// Derived metrics not available are missing.
+	metric := func(data *mes.Data, dataType string) interface{} {
+		v, ok := record.GetMetric(data, dataType)
+		if !ok {
+			return opts.Out.Miss
+		}
+		return formatValue(v, dataType)
+	}
+	value := func(data *mes.Data) interface{} {
+		if len(ts) == 1 {
+			return metric(data, ts[0])
+		}
+		values := make(MetricValues, len(ts))
+		for i, t := range ts {
+			values[i] = &JsonData{Name: t, Value: metric(data, t)}
+		}
+		return values
+	}
+	timings := make([]*TimingData, 0)
+	var pt *TimingData
+	record.ForEachData(func(d interface{}, _ int) {
+		if p, ok := d.(*mes.Parent); ok {
+			timing := TimingData{}
+			timing.Name = p.Name
+			timing.Value = value(&p.Data)
+			timing.Details = make([]*JsonData, 0)
+			pt = &timing
+			timings = append(timings, &timing)
+			return
+		}
+		if !opts.Out.Simple {
+			if c, ok := d.(*mes.Child); ok {
+				js := JsonData{}
+				js.Name = c.Name
+				js.Value = value(&c.Data)
+				pt.Details = append(pt.Details, &js)
 				return
 			}
-			if !opts.Out.Simple {
-				if c, ok := d.(*Child); ok {
-					js := JsonData{}
-					js.Name = c.Name
-					value := c.GetValue(dataType)
-					if opts.Out.Duration == Human && (dataType == CpuSec || dataType == ClockSec) {
-						js.Value = formatSeconds(value)
-					} else {
-						js.Value = value
-					}
-					pt.Details = append(pt.Details, &js)
-					return
-				}
-			}
-		})
-		jsonOut.Timings = timings
+		}
+	})
+	jsonOut.Timings = timings
 
-		jsonSet = append(jsonSet, &jsonOut)
+	// Set diagnostics.
+	for _, d := range record.Diagnostics {
+		jsonOut.Diagnostics = append(jsonOut.Diagnostics, &DiagnosticData{Line: d.Line, Severity: d.Severity, Message: d.Message})
 	}
-	return jsonSet
+	return &jsonOut
+}
+
+// formatValue formats value of dataType according to "-d, --duration" option.
+// Xlsx format keeps seconds to format them as durations in cells without rounding.
+func formatValue(value float64, dataType string) interface{} {
+	if opts.Out.Duration == Human && isSeconds(dataType) && opts.Out.Output != Xlsx {
+		return formatSeconds(value)
+	}
+	if isDerived(dataType) {
+		return roundTo(value, 4)
+	}
+	// Drop floating point noise of computed values (e.g. mean).
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'g', 10, 64), 64)
+	return rounded
+}
+
+// isSeconds reports whether dataType is a duration in seconds.
+func isSeconds(dataType string) bool {
+	return dataType == CpuSec || dataType == ClockSec
+}
+
+// formatCell formats value of table cell and simple format.
+// Integral numbers are not formatted in exponent notation (e.g. 200000000 instead of 2e+08).
+func formatCell(value interface{}) string {
+	if v, ok := value.(float64); ok && v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
 }
 
 func formatSeconds(seconds float64) string {
@@ -242,9 +508,11 @@ func (cli *CLI) FormatTable(data []byte) string {
 }
 
 // Header stores keys with no duplicate.
+// Targets are set if timings have values of multiple targets, and each timing has a column for each target.
 type Header struct {
 	PropertyKeys []string
 	TimingKeys   []*TimingKey
+	Targets      []string
 }
 
 // TimingKey stores keys with parent-child relationship.
@@ -303,15 +571,42 @@ func (header *Header) AddChildKey(parentKey, childKey string) {
 }
 
 // GetHeader returns string array of keys.
+// Keys of timings are suffixed with targets if multiple (e.g. "Keyword Processing (cpusec)").
 func (header *Header) GetKeys() []string {
 	var timingKeys []string
+	add := func(key string) {
+		if len(header.Targets) == 0 {
+			timingKeys = append(timingKeys, key)
+			return
+		}
+		for _, target := range header.Targets {
+			timingKeys = append(timingKeys, fmt.Sprintf("%s (%s)", key, target))
+		}
+	}
 	for _, timingKey := range header.TimingKeys {
-		timingKeys = append(timingKeys, timingKey.ParentKey)
-		timingKeys = append(timingKeys, timingKey.ChildKeys...)
+		add(timingKey.ParentKey)
+		for _, childKey := range timingKey.ChildKeys {
+			add(childKey)
+		}
 	}
 	return append(header.PropertyKeys, timingKeys...)
 }
 
+// GetTargets returns targets of columns in the same order as keys, empty for properties.
+func (header *Header) GetTargets() []string {
+	columns := make([]string, len(header.PropertyKeys))
+	ts := header.Targets
+	if len(ts) == 0 {
+		ts = targets()[:1]
+	}
+	for _, timingKey := range header.TimingKeys {
+		for i := 0; i <= len(timingKey.ChildKeys); i++ {
+			columns = append(columns, ts...)
+		}
+	}
+	return columns
+}
+
 // GetHeader returns header data for table.
 func (cli *CLI) GetHeader(records []*RecordData) Header {
 	var header Header
@@ -326,6 +621,9 @@ func (cli *CLI) GetHeader(records []*RecordData) Header {
 	// Add timing keys.
 	for _, record := range records {
 		for _, timing := range record.Timings {
+			if _, ok := metricValues(timing.Value); ok {
+				header.Targets = targets()
+			}
 			header.AddParentKey(timing.Name)
 			for _, detail := range timing.Details {
 				header.AddChildKey(timing.Name, detail.Name)
@@ -335,20 +633,46 @@ func (cli *CLI) GetHeader(records []*RecordData) Header {
 	return header
 }
 
-// GetData returns table data.
-func (cli *CLI) GetData(records []*RecordData, header Header) [][]string {
+// GetValues returns table data as values of properties and timings.
+// Missing values are replaced with "-m, --missing" option.
+func (cli *CLI) GetValues(records []*RecordData, header Header) [][]interface{} {
 	naWord := opts.Out.Miss
-	var data [][]string
+	var data [][]interface{}
+
+	// timingValues returns values of columns of a timing value, or missing values if not found.
+	timingValues := func(value interface{}, found bool) []interface{} {
+		if len(header.Targets) == 0 {
+			if !found {
+				return []interface{}{naWord}
+			}
+			return []interface{}{value}
+		}
+		m, _ := metricValues(value)
+		values := make([]interface{}, len(header.Targets))
+		for i, target := range header.Targets {
+			v, ok := m[target]
+			if !found || !ok {
+				v = naWord
+			}
+			values[i] = v
+		}
+		return values
+	}
 
 	for _, record := range records {
 		// Get property data.
-		var values []string
+		var values []interface{}
 		for _, propertyKey := range header.PropertyKeys {
+			propertyFound := false
 			for _, property := range record.Properties {
 				if property.Name == propertyKey {
-					values = append(values, fmt.Sprint(property.Value))
+					propertyFound = true
+					values = append(values, property.Value)
 				}
 			}
+			if !propertyFound {
+				values = append(values, naWord)
+			}
 		}
 
 		// Get timing data.
@@ -359,11 +683,11 @@ func (cli *CLI) GetData(records []*RecordData, header Header) [][]string {
 			for _, timing := range record.Timings {
 				if timing.Name == parentKey {
 					parentFound = true
-					values = append(values, fmt.Sprint(timing.Value))
+					values = append(values, timingValues(timing.Value, true)...)
 				}
 			}
 			if !parentFound {
-				values = append(values, naWord)
+				values = append(values, timingValues(nil, false)...)
 			}
 
 			// Get child data.
@@ -374,13 +698,13 @@ func (cli *CLI) GetData(records []*RecordData, header Header) [][]string {
 						for _, detail := range timing.Details {
 							if detail.Name == childKey {
 								childFound = true
-								values = append(values, fmt.Sprint(detail.Value))
+								values = append(values, timingValues(detail.Value, true)...)
 							}
 						}
 					}
 				}
 				if !childFound {
-					values = append(values, naWord)
+					values = append(values, timingValues(nil, false)...)
 				}
 			}
 		}
@@ -389,6 +713,19 @@ func (cli *CLI) GetData(records []*RecordData, header Header) [][]string {
 	return data
 }
 
+// GetData returns table data.
+func (cli *CLI) GetData(records []*RecordData, header Header) [][]string {
+	var data [][]string
+	for _, values := range cli.GetValues(records, header) {
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = formatCell(value)
+		}
+		data = append(data, row)
+	}
+	return data
+}
+
 // FormatHtml formats output data to html table.
 func (cli *CLI) FormatHtml(data []byte) string {
 	var md = cli.FormatTable(data)
@@ -403,21 +740,7 @@ func (cli *CLI) FormatSimple(data []byte) string {
 	json.Unmarshal(data, &records)
 
 	for i, record := range records {
-		// Get property lines.
-		for _, property := range record.Properties {
-			val := fmt.Sprint(property.Value)
-			str += fmt.Sprintf("%s: %s\n", property.Name, val)
-		}
-
-		// Get timing lines.
-		for _, timing := range record.Timings {
-			val := fmt.Sprint(timing.Value)
-			str += fmt.Sprintf("%s: %s\n", timing.Name, val)
-			for _, detail := range timing.Details {
-				val := fmt.Sprint(detail.Value)
-				str += fmt.Sprintf("  %s: %s\n", detail.Name, val)
-			}
-		}
+		str += formatSimpleRecord(record)
 
 		// Add blank line.
 		if i != len(records)-1 {
@@ -426,3 +749,25 @@ func (cli *CLI) FormatSimple(data []byte) string {
 	}
 	return str
 }
+
+// formatSimpleRecord formats a record to simple lines per data.
+func formatSimpleRecord(record *RecordData) string {
+	str := ""
+
+	// Get property lines.
+	for _, property := range record.Properties {
+		val := formatCell(property.Value)
+		str += fmt.Sprintf("%s: %s\n", property.Name, val)
+	}
+
+	// Get timing lines.
+	for _, timing := range record.Timings {
+		val := formatMetricValues(timing.Value)
+		str += fmt.Sprintf("%s: %s\n", timing.Name, val)
+		for _, detail := range timing.Details {
+			val := formatMetricValues(detail.Value)
+			str += fmt.Sprintf("  %s: %s\n", detail.Name, val)
+		}
+	}
+	return str
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteFilesEmptyJson(t *testing.T) {
+	// The normally terminated run is excluded, so results are empty.
+	opts.Out.Terminations = []string{"error"}
+	opts.Out.Output = Json
+	defer func() {
+		opts.Out.Terminations = nil
+		opts.Out.Output = ""
+		opts.Out.Long = false
+		opts.Out.Query = ""
+	}()
+	tests := []struct {
+		name  string
+		long  bool
+		query string
+	}{
+		{"streaming", false, ""},
+		{"query", false, "@"},
+		{"long", true, ""},
+	}
+	for _, tt := range tests {
+		opts.Out.Long, opts.Out.Query = tt.long, tt.query
+		out := new(bytes.Buffer)
+		cli := &CLI{outStream: out, errStream: new(bytes.Buffer)}
+		if err := cli.WriteFiles([]string{"mes/testdata/r7_smp_messag"}); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "[]\n" {
+			t.Errorf("%s: output = %q, want []", tt.name, out)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// durationPattern matches human-readable durations (e.g. 0:01:21).
+var durationPattern = regexp.MustCompile(`^(\d+):(\d\d):(\d\d)$`)
+
+// Number formats of xlsx cells.
+const (
+	xlsxDurationFormat = "[h]:mm:ss"
+	xlsxSecondsFormat  = "0.00"
+	xlsxPercentFormat  = `0.00"%"`
+)
+
+// An xlsxCell is a value of cell with its style.
+type xlsxCell struct {
+	Value interface{}
+	Style int
+}
+
+// xlsxStyles are styles of header and number formats.
+type xlsxStyles struct {
+	Header, Duration, Seconds, Percent int
+}
+
+// newXlsxStyles registers styles to workbook.
+func newXlsxStyles(f *excelize.File) (*xlsxStyles, error) {
+	var styles xlsxStyles
+	var err error
+	if styles.Header, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return nil, err
+	}
+	for style, format := range map[*int]string{
+		&styles.Duration: xlsxDurationFormat,
+		&styles.Seconds:  xlsxSecondsFormat,
+		&styles.Percent:  xlsxPercentFormat,
+	} {
+		format := format
+		if *style, err = f.NewStyle(&excelize.Style{CustomNumFmt: &format}); err != nil {
+			return nil, err
+		}
+	}
+	return &styles, nil
+}
+
+// cell converts value of dataType to numeric cell if possible.
+// Human-readable durations and seconds with "-d human-readable" become time values in days,
+// and other timings of dataType get number format for seconds or percentages.
+func (styles *xlsxStyles) cell(value interface{}, dataType string) xlsxCell {
+	switch v := value.(type) {
+	case string:
+		if results := durationPattern.FindStringSubmatch(v); results != nil {
+			h, _ := strconv.ParseFloat(results[1], 64)
+			m, _ := strconv.ParseFloat(results[2], 64)
+			s, _ := strconv.ParseFloat(results[3], 64)
+			// Excel time value is in days.
+			return xlsxCell{Value: (h*3600 + m*60 + s) / 86400, Style: styles.Duration}
+		}
+	case float64:
+		switch {
+		case isSeconds(dataType) && opts.Out.Duration == Human:
+			return xlsxCell{Value: v / 86400, Style: styles.Duration}
+		case isSeconds(dataType):
+			return xlsxCell{Value: v, Style: styles.Seconds}
+		case dataType == CpuPercent, dataType == ClockPercent, dataType == CoreUtilization:
+			return xlsxCell{Value: v, Style: styles.Percent}
+		}
+	}
+	return xlsxCell{Value: value}
+}
+
+// isTargetRow reports whether timings of normalized record d are values in the unit of targets,
+// not counts or ratios of them (e.g. "count" of "stats" command, "speedup" of "scaling" command).
+func isTargetRow(d *RecordData) bool {
+	for _, p := range d.Properties {
+		switch {
+		case p.Name == "statistic" && p.Value == "count":
+			return false
+		case p.Name == "quantity" && p.Value != "time":
+			return false
+		}
+	}
+	return true
+}
+
+// FormatXlsx formats json data to xlsx workbook with sheets
+// "summary" (the table of other formats), "long" (a row for each timing)
+// and "properties" (a row for each property).
+func (cli *CLI) FormatXlsx(data []byte) (string, error) {
+	var ds []*RecordData
+	json.Unmarshal(data, &ds)
+
+	f := excelize.NewFile()
+	defer f.Close()
+	styles, err := newXlsxStyles(f)
+	if err != nil {
+		return "", err
+	}
+
+	// Summary sheet has timings after properties in each row.
+	header := cli.GetHeader(ds)
+	columnTargets := header.GetTargets()
+	var summary [][]xlsxCell
+	for r, values := range cli.GetValues(ds, header) {
+		row := make([]xlsxCell, len(values))
+		for i, value := range values {
+			dataType := columnTargets[i]
+			if !isTargetRow(ds[r]) {
+				dataType = ""
+			}
+			row[i] = styles.cell(value, dataType)
+		}
+		summary = append(summary, row)
+	}
+
+	// Long and properties sheets identify records by the first property (e.g. file).
+	var long, properties [][]xlsxCell
+	for _, d := range ds {
+		var key interface{}
+		if len(d.Properties) > 0 {
+			key = d.Properties[0].Value
+		}
+		for _, p := range d.Properties {
+			properties = append(properties, []xlsxCell{{Value: key}, {Value: p.Name}, styles.cell(p.Value, "")})
+		}
+	}
+	for _, d := range ds {
+		for _, l := range longData([]*RecordData{d}) {
+			dataType := l.Metric
+			if !isTargetRow(d) {
+				dataType = ""
+			}
+			long = append(long, []xlsxCell{{Value: l.File}, {Value: l.Parent}, {Value: l.Child}, {Value: l.Metric}, styles.cell(l.Value, dataType)})
+		}
+	}
+
+	keyName := "file"
+	if len(header.PropertyKeys) > 0 {
+		keyName = header.PropertyKeys[0]
+	}
+	sheets := []struct {
+		name string
+		keys []string
+		rows [][]xlsxCell
+	}{
+		{"summary", header.GetKeys(), summary},
+		{"long", []string{keyName, "parent", "child", "metric", "value"}, long},
+		{"properties", []string{keyName, "name", "value"}, properties},
+	}
+	for i, sheet := range sheets {
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), sheet.name)
+		} else {
+			_, err = f.NewSheet(sheet.name)
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := writeXlsxSheet(f, sheet.name, sheet.keys, sheet.rows, styles); err != nil {
+			return "", err
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeXlsxSheet writes bold header row frozen at the top and rows of cells to sheet.
+func writeXlsxSheet(f *excelize.File, sheet string, keys []string, rows [][]xlsxCell, styles *xlsxStyles) error {
+	for i, key := range keys {
+		name, _ := excelize.CoordinatesToCellName(i+1, 1)
+		if err := f.SetCellValue(sheet, name, key); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, name, name, styles.Header); err != nil {
+			return err
+		}
+	}
+	for r, row := range rows {
+		for c, cell := range row {
+			name, _ := excelize.CoordinatesToCellName(c+1, r+2)
+			if err := f.SetCellValue(sheet, name, cell.Value); err != nil {
+				return err
+			}
+			if cell.Style != 0 {
+				if err := f.SetCellStyle(sheet, name, name, cell.Style); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+}
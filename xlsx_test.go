@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"lsti/mes"
+)
+
+// xlsxSummaryFormats formats normalized data to xlsx workbook, and returns
+// number formats of cells of summary sheet (empty for cells without number format).
+func xlsxSummaryFormats(t *testing.T, ds []*RecordData, cells []string) map[string]string {
+	t.Helper()
+	data, err := json.Marshal(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := &CLI{errStream: new(bytes.Buffer)}
+	book, err := cli.FormatXlsx(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(strings.NewReader(book))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	formats := make(map[string]string)
+	for _, cell := range cells {
+		id, err := f.GetCellStyle("summary", cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		style, err := f.GetStyle(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if style.CustomNumFmt != nil {
+			formats[cell] = *style.CustomNumFmt
+		}
+	}
+	return formats
+}
+
+// xlsxTestRecords returns runs of the same input with 1 and 2 CPUs.
+func xlsxTestRecords() []*mes.Record {
+	var records []*mes.Record
+	for _, run := range []struct {
+		numCpus          int64
+		elapsed, element float64
+	}{{1, 100, 90}, {2, 60, 50}} {
+		record := &mes.Record{InputFile: "model.k", NumCpus: run.numCpus, ElapsedTime: run.elapsed}
+		record.AddParent("Element processing", run.element, 90, run.element, 90)
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestFormatXlsxStyles(t *testing.T) {
+	opts.Out.Output = Xlsx
+	opts.Out.Duration = Human
+	opts.Out.Target = ClockSec
+	opts.Stats.Percentiles = nil
+	opts.Scaling.GroupBy = "inputFile"
+	cli := &CLI{errStream: new(bytes.Buffer)}
+
+	// Columns of stats are statistic and Element processing, rows are count, min, ...
+	stats, err := cli.NormalizeStats(xlsxTestRecords())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Columns of scaling are group, numCpus, runs, quantity, elapsedTime and Element processing,
+	// rows are time, speedup and efficiency of 1 and 2 CPUs, and serialFraction.
+	scaling, err := cli.NormalizeScaling(xlsxTestRecords())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ds   []*RecordData
+		want map[string]string
+	}{
+		{"stats", stats, map[string]string{"B2": "", "B3": xlsxDurationFormat, "B7": xlsxDurationFormat}},
+		{"scaling", scaling, map[string]string{"F2": xlsxDurationFormat, "F3": "", "F4": "", "F5": xlsxDurationFormat, "F8": ""}},
+	}
+	for _, tt := range tests {
+		var cells []string
+		for cell := range tt.want {
+			cells = append(cells, cell)
+		}
+		formats := xlsxSummaryFormats(t, tt.ds, cells)
+		for cell, want := range tt.want {
+			if formats[cell] != want {
+				t.Errorf("%s: number format of %s = %q, want %q", tt.name, cell, formats[cell], want)
+			}
+		}
+	}
+}